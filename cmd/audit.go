@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brandnova/nova-horizon-cli/internal/audit"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSession string
+	auditPath    string
+	auditSince   string
+	auditUntil   string
+	auditJSON    bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the log of tool actions the agent took in this directory",
+	Long: `Show the audit log (.nova/audit.jsonl) of the working directory.
+
+Every tool call is recorded with its session, arguments, affected paths, content
+hashes before and after, exit code and how it was approved.
+
+Times for --since and --until may be RFC 3339 ("2024-05-01T14:00:00Z"), a date
+("2024-05-01"), a local date and time ("2024-05-01 14:00") or a duration ago ("2h").`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(false)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		filter := audit.Filter{Session: auditSession, Path: auditPath}
+		if auditSince != "" {
+			if filter.Since, err = parseAuditTime(auditSince, false); err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+		}
+		if auditUntil != "" {
+			if filter.Until, err = parseAuditTime(auditUntil, true); err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+		}
+
+		entries, err := audit.Read(audit.Path(cfg.WorkDir), filter)
+		if err != nil {
+			return err
+		}
+
+		if auditJSON {
+			enc := json.NewEncoder(os.Stdout)
+			for _, e := range entries {
+				if err := enc.Encode(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("No matching audit entries.")
+			return nil
+		}
+		for _, e := range entries {
+			printAuditEntry(e)
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditSession, "session", "", "Only show this session (a prefix is enough)")
+	auditCmd.Flags().StringVar(&auditPath, "path", "", "Only show actions on this file, directory or glob")
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "Only show actions at or after this time")
+	auditCmd.Flags().StringVar(&auditUntil, "until", "", "Only show actions at or before this time")
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Print matching entries as JSON lines")
+	rootCmd.AddCommand(auditCmd)
+}
+
+func printAuditEntry(e audit.Entry) {
+	fmt.Printf("%s  %s  #%d  %s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Session, e.Step, color.CyanString(e.Tool))
+
+	for _, p := range e.Paths {
+		fmt.Printf("  %s", p)
+		if change := auditChange(e, p); change != "" {
+			fmt.Printf(" (%s)", change)
+		}
+	}
+	if e.ExitCode != nil {
+		fmt.Printf("  exit=%d", *e.ExitCode)
+	}
+	if e.Approval != "" {
+		fmt.Printf("  approval=%s", e.Approval)
+	}
+	if e.DryRun {
+		fmt.Print("  [dry run]")
+	}
+	fmt.Println()
+
+	if e.Error != "" {
+		color.Red("    error: %s", e.Error)
+	}
+}
+
+// auditChange summarizes how an entry's hashes say path changed
+func auditChange(e audit.Entry, path string) string {
+	before, hadBefore := e.Before[path]
+	after, hadAfter := e.After[path]
+	if !hadBefore || !hadAfter {
+		return ""
+	}
+	switch {
+	case before == after:
+		return "unchanged"
+	case before == "":
+		return "created"
+	case after == "":
+		return "deleted"
+	default:
+		return "modified"
+	}
+}
+
+// parseAuditTime parses a --since/--until value. A bare date used as an upper
+// bound covers the whole day.
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if endOfDay {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", value)
+}
@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/config"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configProject bool
+	configForce   bool
+	configReveal  bool
+	showEffective bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Create, inspect and edit configuration",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactively create a config file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configTarget()
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err == nil && !configForce {
+			return fmt.Errorf("%s already exists (use --force to overwrite, or 'config set' to change single keys)", path)
+		}
+
+		reader := bufio.NewReader(os.Stdin)
+		defaults := config.Defaults()
+		values := make(map[string]string)
+
+		fmt.Printf("Creating %s\n", path)
+		if !configProject {
+			fmt.Println("Leave the API key empty to use the GEMINI_API_KEY environment variable instead.")
+			key, err := ask(reader, "Gemini API key (input is visible)", "")
+			if err != nil {
+				return err
+			}
+			if key != "" {
+				values["api_key"] = key
+			}
+		}
+
+		prompts := []struct{ key, label, def string }{
+			{"model", "Model", defaults.Model},
+			{"max_steps", "Maximum agent steps", fmt.Sprint(defaults.MaxSteps)},
+			{"allow_run", "Allow program execution (true/false)", fmt.Sprint(defaults.AllowRun)},
+		}
+		for _, p := range prompts {
+			// A project file cannot hold these; they would only be ignored
+			if key, ok := config.LookupKey(p.key); ok && key.UserOnly && configProject {
+				continue
+			}
+			value, err := ask(reader, p.label, p.def)
+			if err != nil {
+				return err
+			}
+			values[p.key] = value
+		}
+
+		if err := config.WriteValues(path, values); err != nil {
+			return err
+		}
+		color.Green("Wrote %s", path)
+		return nil
+	},
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, ok := config.LookupKey(args[0])
+		if !ok {
+			return fmt.Errorf("unknown key %q (see 'nova-hrzn config show')", args[0])
+		}
+
+		cfg, err := loadConfig(false)
+		if err != nil {
+			return err
+		}
+
+		value := key.Format(key.Get(cfg))
+		if key.Secret && !configReveal {
+			value = config.MaskSecret(value)
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a key in the user (or --project) config file",
+	Long: `Set a key in the user config file, or the project's .nova/config.toml with --project.
+Use profiles.<name>.<key> to set a key inside a profile.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configTarget()
+		if err != nil {
+			return err
+		}
+		if err := config.SetFileValue(path, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Set %s in %s\n", args[0], path)
+		return nil
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a key from the user (or --project) config file",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := configTarget()
+		if err != nil {
+			return err
+		}
+		if err := config.UnsetFileValue(path, args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("Removed %s from %s\n", args[0], path)
+		return nil
+	},
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Show which config files and data directories are used",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := resolveWorkDir()
+		if err != nil {
+			return err
+		}
+
+		userPath := config.UserConfigPath()
+		fmt.Printf("user:    %s%s\n", userPath, missingSuffix(userPath))
+		projectPath := config.ProjectConfigFile(dir)
+		fmt.Printf("project: %s%s\n", projectPath, missingSuffix(projectPath))
+		if stateDir, err := config.StateDir(); err == nil {
+			fmt.Printf("state:   %s\n", stateDir)
+		}
+		if logDir, err := config.LogDir(); err == nil {
+			fmt.Printf("logs:    %s\n", logDir)
+		}
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "List configuration keys (with --effective, their resolved values and sources)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !showEffective {
+			for _, key := range config.Keys {
+				fmt.Printf("%-24s %-16s %s\n", key.Name, key.Kind, key.Description)
+			}
+			return nil
+		}
+
+		cfg, err := loadConfig(false)
+		if err != nil {
+			return err
+		}
+
+		if cfg.Profile != "" {
+			fmt.Printf("# profile: %s\n", cfg.Profile)
+		}
+		for _, key := range config.Keys {
+			value := key.Format(key.Get(cfg))
+			if key.Secret && !configReveal {
+				value = config.MaskSecret(value)
+			}
+			source := cfg.Sources[key.Name]
+			if source == "" {
+				source = "default"
+			}
+			fmt.Printf("%-24s = %-30s # %s\n", key.Name, quoteIfNeeded(value), source)
+		}
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check every config file and environment variable for unknown keys and invalid values",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := resolveWorkDir()
+		if err != nil {
+			return err
+		}
+
+		files := config.ConfigFiles(dir)
+		var problems []error
+		for _, path := range files {
+			errs := config.ValidateFile(path, path != config.UserConfigPath())
+			if len(errs) == 0 {
+				fmt.Printf("%s: OK\n", path)
+			}
+			problems = append(problems, errs...)
+		}
+
+		// Catch environment and profile-selection errors, which only show up when layering
+		if len(problems) == 0 {
+			if _, err := loadConfig(false); err != nil {
+				problems = append(problems, err)
+			}
+		}
+
+		if len(files) == 0 {
+			fmt.Println("No config files found; using defaults and environment variables.")
+		}
+		for _, p := range problems {
+			color.Red("%v", p)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("found %d problem(s)", len(problems))
+		}
+		return nil
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{configInitCmd, configSetCmd, configUnsetCmd} {
+		c.Flags().BoolVar(&configProject, "project", false, "Use the project's .nova/config.toml instead of the user config")
+	}
+	configInitCmd.Flags().BoolVar(&configForce, "force", false, "Overwrite an existing config file")
+	configGetCmd.Flags().BoolVar(&configReveal, "reveal", false, "Show secret values unmasked")
+	configShowCmd.Flags().BoolVar(&configReveal, "reveal", false, "Show secret values unmasked")
+	configShowCmd.Flags().BoolVar(&showEffective, "effective", false, "Show resolved values and where each one came from")
+
+	configCmd.AddCommand(configInitCmd, configGetCmd, configSetCmd, configUnsetCmd, configPathCmd, configShowCmd, configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// configTarget returns the config file that init/set/unset should modify
+func configTarget() (string, error) {
+	if !configProject {
+		return config.UserConfigPath(), nil
+	}
+	dir, err := resolveWorkDir()
+	if err != nil {
+		return "", err
+	}
+	return config.ProjectConfigFile(dir), nil
+}
+
+func ask(reader *bufio.Reader, label, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", label, def)
+	} else {
+		fmt.Printf("%s: ", label)
+	}
+
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return def, nil
+	}
+	return input, nil
+}
+
+func missingSuffix(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return " (not found)"
+	}
+	return ""
+}
+
+func quoteIfNeeded(s string) string {
+	if s == "" || strings.ContainsAny(s, " \n\t") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/permissions"
+	"github.com/fatih/color"
+)
+
+// askPermission asks whether a tool call may go ahead. Without a rule to remember,
+// only once or deny are offered. End of input counts as deny.
+func askPermission(question string, remember string) permissions.Choice {
+	for {
+		fmt.Println()
+		color.New(color.FgYellow, color.Bold).Println(question)
+		if remember == "" {
+			color.New(color.FgYellow).Print("Allow [o]nce or [d]eny? ")
+		} else {
+			fmt.Printf("  \"Always\" adds: %s\n", remember)
+			color.New(color.FgYellow).Print("Allow [o]nce, always this [s]ession, always for this [p]roject, or [d]eny? ")
+		}
+
+		answer, err := stdin.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			return permissions.Refuse
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "o", "once", "y", "yes":
+			return permissions.Once
+		case "d", "deny", "n", "no":
+			return permissions.Refuse
+		case "s", "session":
+			if remember != "" {
+				return permissions.Session
+			}
+		case "p", "project":
+			if remember != "" {
+				return permissions.Project
+			}
+		}
+		if remember == "" {
+			color.Yellow("Please answer o or d.")
+		} else {
+			color.Yellow("Please answer o, s, p or d.")
+		}
+	}
+}
+
+// reviewPlan shows a proposed plan and lets the user approve, edit or reject it
+func reviewPlan(plan string) (string, bool, error) {
+	for {
+		fmt.Println()
+		color.New(color.FgCyan, color.Bold).Println("Proposed plan:")
+		fmt.Println(plan)
+		fmt.Println()
+		color.New(color.FgYellow).Print("[a]pprove, [e]dit or [r]eject? ")
+
+		answer, err := stdin.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+			return "", false, nil
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "a", "approve", "y", "yes":
+			return plan, true, nil
+		case "e", "edit":
+			edited, err := editText(plan)
+			if err != nil {
+				return "", false, err
+			}
+			if strings.TrimSpace(edited) == "" {
+				color.Yellow("The edited plan is empty; keeping the previous version.")
+				continue
+			}
+			plan = strings.TrimSpace(edited)
+		case "r", "reject", "n", "no":
+			return "", false, nil
+		default:
+			color.Yellow("Please answer a, e or r.")
+		}
+	}
+}
+
+// editText opens text in $VISUAL or $EDITOR and returns the saved result
+func editText(text string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	f, err := os.CreateTemp("", "nova-plan-*.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	f.Close()
+
+	// The editor setting may carry arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor %s failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited plan: %w", err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/fatih/color"
+)
+
+// runInterruptible runs fn with a context that is cancelled by the first Ctrl+C.
+// A second Ctrl+C while fn is still running exits the process.
+func runInterruptible(fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-sigCh:
+			fmt.Println()
+			color.Yellow("Cancelling current step (press Ctrl+C again to exit)...")
+			cancel()
+		case <-done:
+			return
+		}
+
+		select {
+		case <-sigCh:
+			fmt.Println()
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/brandnova/nova-horizon-cli/internal/permissions"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// shellPermissions keeps "always this session" rules across the prompts of a shell session
+var shellPermissions *permissions.Engine
+
+var permissionsTrust bool
+
+var permissionsCmd = &cobra.Command{
+	Use:   "permissions",
+	Short: "Show the permission rules for tool calls in this directory",
+	Long: `Show the defaults and the project rules (.nova/permissions.toml) that decide whether
+the agent may write, delete, move, create or run something without asking.
+
+Rules are added by answering "always for this project" at a permission prompt, or
+by editing the file:
+
+  [[rule]]
+  tool = "write_file"
+  path = "src/**"
+  action = "allow"
+
+  [[rule]]
+  tool = "run_file"
+  command = "scripts/test.sh *"
+  action = "allow"
+
+A matching deny rule always wins; otherwise the last matching rule decides. Deletes
+always ask unless a rule denies them.
+
+The rules file is part of the project, so its allow rules are ignored until you
+review it and run "nova-hrzn permissions --trust". Trust is kept in your own state
+directory and lapses whenever the file changes, except through your own answers to
+permission prompts. Deny and ask rules always apply.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(false)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		engine, err := permissions.Load(cfg.WorkDir)
+		if err != nil {
+			return err
+		}
+
+		defaults := permissions.Defaults(cfg.AllowRun, cfg.ApplyDiff)
+		names := make([]string, 0, len(defaults))
+		for tool := range defaults {
+			names = append(names, tool)
+		}
+		sort.Strings(names)
+
+		color.New(color.FgCyan, color.Bold).Println("Defaults:")
+		for _, tool := range names {
+			action := string(defaults[tool])
+			if permissions.AlwaysAsk[tool] {
+				action += " (always)"
+			}
+			fmt.Printf("  %-17s %s\n", tool, action)
+		}
+		fmt.Println("  other tools       allow")
+
+		_, project := engine.Rules()
+		printRules("Project rules ("+engine.File()+"):", project)
+
+		if permissionsTrust {
+			if len(project) == 0 {
+				return fmt.Errorf("there are no project rules to trust")
+			}
+			if err := engine.Trust(); err != nil {
+				return err
+			}
+			color.Green("Trusted the project rules above; they apply until the file changes.")
+		} else if engine.Untrusted() {
+			warnUntrusted(engine)
+		}
+		return nil
+	},
+}
+
+func init() {
+	permissionsCmd.Flags().BoolVar(&permissionsTrust, "trust", false, "Trust the allow rules in the project's permissions file as it is now")
+	rootCmd.AddCommand(permissionsCmd)
+}
+
+func warnUntrusted(engine *permissions.Engine) {
+	color.Yellow("Allow rules in %s are ignored until you review them and run 'nova-hrzn permissions --trust'.", engine.File())
+}
+
+// projectPermissions returns the permission engine for workDir. The shell reuses one
+// engine so session rules last until it exits; project rules are re-read every time.
+func projectPermissions(workDir string) (*permissions.Engine, error) {
+	if shellPermissions != nil && shellPermissions.File() == filepath.Join(workDir, permissions.RelPath()) {
+		return shellPermissions, shellPermissions.Reload()
+	}
+	engine, err := permissions.Load(workDir)
+	if err != nil {
+		return nil, err
+	}
+	if engine.Untrusted() {
+		warnUntrusted(engine)
+	}
+	shellPermissions = engine
+	return engine, nil
+}
+
+// listPermissions implements /permissions
+func listPermissions() error {
+	cfg, err := loadConfig(false)
+	if err != nil {
+		return err
+	}
+	engine, err := projectPermissions(cfg.WorkDir)
+	if err != nil {
+		return err
+	}
+	session, project := engine.Rules()
+	printRules("Session rules:", session)
+	printRules("Project rules ("+engine.File()+"):", project)
+	return nil
+}
+
+func printRules(title string, rules []permissions.Rule) {
+	color.New(color.FgCyan, color.Bold).Println(title)
+	if len(rules) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+	for _, r := range rules {
+		fmt.Printf("  %s\n", r)
+	}
+}
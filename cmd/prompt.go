@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/brandnova/nova-horizon-cli/internal/agent"
+	"github.com/spf13/cobra"
+)
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Inspect the system prompt sent to the model",
+}
+
+var promptShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective system prompt for the current directory and flags",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(false)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		agentConfig, err := buildAgentConfig(cfg)
+		if err != nil {
+			return err
+		}
+
+		prompt, err := agent.BuildSystemPrompt(agentConfig)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(prompt)
+		return nil
+	},
+}
+
+func init() {
+	promptCmd.AddCommand(promptShowCmd)
+	rootCmd.AddCommand(promptCmd)
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +11,8 @@ import (
 
 	"github.com/brandnova/nova-horizon-cli/internal/agent"
 	"github.com/brandnova/nova-horizon-cli/internal/config"
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -16,11 +20,24 @@ var (
 	workDir   string
 	verbose   bool
 	dryRun    bool
+	patchFile string
 	model     string
 	maxSteps  int
+	retries   int
+	ctxLimit  int
 	allowRun  bool
 	applyDiff bool
 	showInfo  bool
+	profile   string
+	cfgFile   string
+	logLevel  string
+	logFile   string
+	logFormat string
+
+	allowDirty bool
+	gitBranch  string
+	planMode   bool
+	readOnly   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -34,43 +51,92 @@ Examples:
   nova-hrzn --dir ./myproject "List all files in this directory"
   nova-hrzn --verbose --allow-run "Execute my test script"
   nova-hrzn --info`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		// Just show info if requested
-		if showInfo {
-			printBanner()
-			printInfo()
-			return nil
-		}
-
-		// Interactive shell mode if no args
-		if len(args) == 0 {
-			printBanner()
-			printInfo()
-			return runShell()
-		}
+	Args: cobra.ArbitraryArgs,
 
-		return runAgent(args[0])
-	},
+	// main prints returned errors; usage is only useful for flag mistakes
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 func init() {
+	// Assigned here rather than in the literal: the run path reads rootCmd's flags
+	rootCmd.RunE = runRoot
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if cfgFile != "" {
+			path, err := filepath.Abs(config.ExpandHome(cfgFile))
+			if err != nil {
+				return fmt.Errorf("invalid config file path: %w", err)
+			}
+			config.SetConfigFile(path)
+		}
+
+		// Honour logging flags while the config itself is loaded; loadConfig
+		// reconfigures logging once config files and environment are known
+		flags := cmd.Flags()
+		if flags.Changed("log-level") || flags.Changed("log-file") || flags.Changed("log-format") {
+			path, err := config.LogFilePath(logFile)
+			if err != nil {
+				return err
+			}
+			return logger.Setup(logger.Options{Level: logLevel, Format: logFormat, File: path})
+		}
+		return nil
+	}
+
+	defaults := config.Defaults()
 	rootCmd.PersistentFlags().StringVarP(&workDir, "dir", "d", "", "Working directory (default: current directory)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what would be done without making changes")
-	rootCmd.PersistentFlags().StringVar(&model, "model", "gemini-2.5-flash", "Model to use")
-	rootCmd.PersistentFlags().IntVar(&maxSteps, "max-steps", 10, "Maximum agent loop iterations")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Simulate file changes in memory, skip running programs and show the resulting diff")
+	rootCmd.PersistentFlags().StringVar(&patchFile, "patch", "", "With --dry-run, also write the simulated changes to this file as a patch")
+	rootCmd.PersistentFlags().StringVar(&model, "model", defaults.Model, "Model to use")
+	rootCmd.PersistentFlags().IntVar(&maxSteps, "max-steps", defaults.MaxSteps, "Maximum agent loop iterations")
+	rootCmd.PersistentFlags().IntVar(&retries, "max-retries", defaults.MaxRetries, "Maximum retries for rate-limited or failed API calls")
+	rootCmd.PersistentFlags().IntVar(&ctxLimit, "context-limit", defaults.ContextLimit, "Token count at which older conversation history is summarized (0 disables)")
 	rootCmd.PersistentFlags().BoolVar(&allowRun, "allow-run", false, "Allow execution of programs")
 	rootCmd.PersistentFlags().BoolVar(&applyDiff, "apply", false, "Automatically apply file changes without confirmation")
+	rootCmd.PersistentFlags().BoolVar(&planMode, "plan", false, "Propose a plan using read-only tools and wait for approval before changing anything")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "Only read and explain code: file changes and program execution are disabled")
+	rootCmd.PersistentFlags().BoolVar(&allowDirty, "allow-dirty", false, "Start even if the git working tree has uncommitted changes")
+	rootCmd.PersistentFlags().StringVar(&gitBranch, "branch", "", "Git branch to switch to (created if missing) before making changes")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Config file to use instead of the user config (default: $XDG_CONFIG_HOME/nova-horizon/config.toml)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Configuration profile to use (default: $NOVA_PROFILE or the config's \"profile\" key)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", defaults.Log.Level, "Minimum log level: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Write logs to this file instead of stderr (relative paths are in the log directory)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", defaults.Log.Format, "Log format: text or json")
 	rootCmd.PersistentFlags().BoolVar(&showInfo, "info", false, "Show information about Nova Horizon")
 }
 
+func runRoot(cmd *cobra.Command, args []string) error {
+	// Just show info if requested
+	if showInfo {
+		printBanner()
+		printInfo()
+		return nil
+	}
+
+	// Interactive shell mode if no args
+	if len(args) == 0 {
+		printBanner()
+		printInfo()
+		return runShell()
+	}
+
+	return runPrompt(args[0])
+}
+
+// stdin is shared by the shell and confirmation prompts so buffered input isn't lost
+var stdin = bufio.NewReader(os.Stdin)
+
 func runShell() error {
-	reader := bufio.NewReader(os.Stdin)
-	fmt.Println("Entering interactive mode. Type 'exit' to quit.")
+	fmt.Println("Entering interactive mode. Type 'exit' to quit or /help for shell commands.")
 
 	for {
-		fmt.Print("\nnova-hrzn> ")
-		input, err := reader.ReadString('\n')
+		if shellMode == "ask" {
+			fmt.Print("\nnova-hrzn (ask)> ")
+		} else {
+			fmt.Print("\nnova-hrzn> ")
+		}
+		input, err := stdin.ReadString('\n')
 		if err != nil {
 			return err
 		}
@@ -85,49 +151,46 @@ func runShell() error {
 			continue
 		}
 
-		if err := runAgent(input); err != nil {
+		if handled, err := handleShellCommand(input); handled {
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			continue
+		}
+
+		if err := runPrompt(input); err != nil {
 			fmt.Printf("Error: %v\n", err)
 		}
 	}
 	return nil
 }
 
-func runAgent(prompt string) error {
-	cfg, err := config.LoadConfig()
+// runPrompt runs the agent for a single prompt, cancelling the current step on Ctrl+C
+func runPrompt(prompt string) error {
+	err := runInterruptible(func(ctx context.Context) error {
+		return runAgent(ctx, prompt)
+	})
+	if errors.Is(err, context.Canceled) {
+		color.Yellow("Cancelled.")
+		return nil
+	}
+	return err
+}
+
+func runAgent(ctx context.Context, prompt string) error {
+	cfg, err := loadConfig(true)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	// Resolve working directory (logic same as before, ensures absolute path)
-	resolvedWorkDir := workDir
-	if resolvedWorkDir == "" {
-		wd, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get working directory: %w", err)
-		}
-		resolvedWorkDir = wd
-	} else {
-		absPath, err := filepath.Abs(resolvedWorkDir)
-		if err != nil {
-			return fmt.Errorf("invalid working directory: %w", err)
-		}
-		resolvedWorkDir = absPath
+	agentConfig, err := buildAgentConfig(cfg)
+	if err != nil {
+		return err
 	}
 
 	// Create and run agent
-	agentConfig := &agent.Config{
-		APIKey:    cfg.APIKey,
-		Model:     model,
-		WorkDir:   resolvedWorkDir,
-		Verbose:   verbose,
-		DryRun:    dryRun,
-		MaxSteps:  maxSteps,
-		AllowRun:  allowRun,
-		ApplyDiff: applyDiff,
-	}
-
 	ag := agent.NewAgent(agentConfig)
-	return ag.Run(prompt)
+	return ag.Run(ctx, prompt)
 }
 
 func printBanner() {
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/brandnova/nova-horizon-cli/internal/agent"
+	"github.com/brandnova/nova-horizon-cli/internal/config"
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+)
+
+// loadConfig layers config files and environment variables (see config.Load) and then
+// applies any command-line flags the user set explicitly.
+func loadConfig(requireAPIKey bool) (*config.Config, error) {
+	startDir, err := resolveWorkDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *config.Config
+	if requireAPIKey {
+		cfg, err = config.LoadConfig(startDir, profile)
+	} else {
+		cfg, err = config.Load(startDir, profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applyFlags(cfg)
+
+	if cfg.Verbose && cfg.Sources["log.level"] == "" {
+		cfg.Log.Level = "info"
+	}
+	logger.AddSecret(cfg.APIKey)
+	logPath, err := config.LogFilePath(cfg.Log.File)
+	if err != nil {
+		return nil, err
+	}
+	if err := logger.Setup(logger.Options{Level: cfg.Log.Level, Format: cfg.Log.Format, File: logPath}); err != nil {
+		return nil, err
+	}
+	for _, warning := range cfg.Warnings {
+		slog.Warn(warning)
+	}
+
+	if cfg.WorkDir == "" {
+		cfg.WorkDir = startDir
+	} else if cfg.WorkDir, err = filepath.Abs(config.ExpandHome(cfg.WorkDir)); err != nil {
+		return nil, fmt.Errorf("invalid working directory: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// applyFlags overrides cfg with flags that were set on the command line
+func applyFlags(cfg *config.Config) {
+	flags := rootCmd.PersistentFlags()
+	set := func(flag, key string, apply func()) {
+		if flags.Changed(flag) {
+			apply()
+			cfg.Sources[key] = "flag --" + flag
+		}
+	}
+
+	set("profile", "profile", func() {})
+	set("dir", "work_dir", func() { cfg.WorkDir = workDir })
+	set("verbose", "verbose", func() { cfg.Verbose = verbose })
+	set("dry-run", "dry_run", func() { cfg.DryRun = dryRun })
+	set("model", "model", func() { cfg.Model = model })
+	set("max-steps", "max_steps", func() { cfg.MaxSteps = maxSteps })
+	set("max-retries", "max_retries", func() { cfg.MaxRetries = retries })
+	set("context-limit", "context_limit", func() { cfg.ContextLimit = ctxLimit })
+	set("allow-run", "allow_run", func() { cfg.AllowRun = allowRun })
+	set("apply", "apply", func() { cfg.ApplyDiff = applyDiff })
+	set("plan", "plan", func() { cfg.Plan = planMode })
+	set("read-only", "read_only", func() { cfg.ReadOnly = readOnly })
+	set("allow-dirty", "git.allow_dirty", func() { cfg.Git.AllowDirty = allowDirty })
+	set("branch", "git.branch", func() { cfg.Git.Branch = gitBranch })
+	set("log-level", "log.level", func() { cfg.Log.Level = logLevel })
+	set("log-file", "log.file", func() { cfg.Log.File = logFile })
+	set("log-format", "log.format", func() { cfg.Log.Format = logFormat })
+}
+
+// buildAgentConfig combines the loaded config with shell state
+func buildAgentConfig(cfg *config.Config) (*agent.Config, error) {
+	instructions, err := config.LoadInstructions(cfg.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := projectPermissions(cfg.WorkDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if patchFile != "" && !cfg.DryRun {
+		return nil, fmt.Errorf("--patch only applies to dry runs (add --dry-run)")
+	}
+
+	readOnly := cfg.ReadOnly
+	if shellMode != "" {
+		readOnly = shellMode == "ask"
+	}
+
+	return &agent.Config{
+		APIKey:     cfg.APIKey,
+		Model:      cfg.Model,
+		WorkDir:    cfg.WorkDir,
+		Verbose:    cfg.Verbose,
+		DryRun:     cfg.DryRun,
+		PatchFile:  patchFile,
+		MaxSteps:   cfg.MaxSteps,
+		MaxRetries: cfg.MaxRetries,
+		AllowRun:   cfg.AllowRun,
+		ApplyDiff:  cfg.ApplyDiff,
+
+		ContextTokenLimit:  cfg.ContextLimit,
+		SystemPrompt:       cfg.SystemPrompt,
+		SystemPromptAppend: cfg.SystemPromptAppend,
+		Instructions:       config.FormatInstructions(instructions),
+		PinnedFiles:        pinnedPatterns,
+		ToolPolicy:         toolPolicy(cfg),
+		Git: agent.GitOptions{
+			Enabled:    cfg.Git.Enabled,
+			AllowDirty: cfg.Git.AllowDirty,
+			AutoCommit: cfg.Git.AutoCommit,
+			Branch:     cfg.Git.Branch,
+		},
+		ReadOnly:   readOnly,
+		Plan:       cfg.Plan,
+		ReviewPlan: reviewPlan,
+
+		Permissions:   engine,
+		AskPermission: askPermission,
+	}, nil
+}
+
+// toolPolicy converts the [tools] config section into a tools.Policy
+func toolPolicy(cfg *config.Config) tools.Policy {
+	policy := tools.DefaultPolicy()
+	policy.MaxFileSize = int64(cfg.Tools.MaxFileSize)
+	policy.ExecTimeout = cfg.Tools.ExecTimeout
+	policy.RunExtensions = cfg.Tools.RunExtensions
+	policy.WriteExtensions = cfg.Tools.WriteExtensions
+	policy.ProtectedPaths = cfg.Tools.ProtectedPaths
+	policy.MaxFilesWritten = cfg.Tools.MaxFilesWritten
+	policy.CreateDirs = cfg.Tools.CreateDirs
+	return policy
+}
+
+// resolveWorkDir returns the absolute working directory from --dir or the current directory
+func resolveWorkDir() (string, error) {
+	if workDir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to get working directory: %w", err)
+		}
+		return wd, nil
+	}
+
+	absPath, err := filepath.Abs(workDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory: %w", err)
+	}
+	return absPath, nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/fatih/color"
+)
+
+// pinnedPatterns are the /pin globs for the current shell session
+var pinnedPatterns []string
+
+// shellMode is the /mode chosen in the shell: "ask", "code", or "" for the config default
+var shellMode string
+
+// handleShellCommand runs a slash command typed in the interactive shell.
+// It reports false if input is not a slash command.
+func handleShellCommand(input string) (bool, error) {
+	if !strings.HasPrefix(input, "/") {
+		return false, nil
+	}
+
+	fields := strings.Fields(input)
+	name, args := fields[0], fields[1:]
+
+	switch name {
+	case "/pin":
+		return true, pinFiles(args)
+	case "/unpin":
+		return true, unpinFiles(args)
+	case "/pins":
+		return true, listPins()
+	case "/undo":
+		return true, undoShell(args)
+	case "/mode":
+		return true, setShellMode(args)
+	case "/permissions":
+		return true, listPermissions()
+	case "/help":
+		printShellHelp()
+		return true, nil
+	default:
+		return true, fmt.Errorf("unknown command %s (type /help for a list)", name)
+	}
+}
+
+func printShellHelp() {
+	fmt.Println(`Shell commands:
+  /pin <glob>...     Pin matching files to the context of every request
+  /unpin [glob]...   Remove pins (all pins if no glob is given)
+  /pins              List pinned files and their total size
+  /undo [step]       Roll back the last agent run (from step n onwards)
+  /mode [ask|code]   Switch between read-only explanations and making changes
+  /permissions       List this session's and the project's permission rules
+  /help              Show this help
+  exit, quit         Leave the shell`)
+}
+
+// setShellMode implements /mode: without an argument it shows the current mode
+func setShellMode(args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("usage: /mode [ask|code]")
+	}
+	if len(args) == 0 {
+		mode := shellMode
+		if mode == "" {
+			cfg, err := loadConfig(false)
+			if err != nil {
+				return err
+			}
+			mode = "code"
+			if cfg.ReadOnly {
+				mode = "ask"
+			}
+		}
+		fmt.Printf("Mode: %s\n", mode)
+		return nil
+	}
+
+	switch args[0] {
+	case "ask":
+		fmt.Println("Ask mode: the agent reads and explains code but cannot change files or run programs")
+	case "code":
+		fmt.Println("Code mode: the agent can change files")
+	default:
+		return fmt.Errorf("unknown mode %q (use ask or code)", args[0])
+	}
+	shellMode = args[0]
+	return nil
+}
+
+func pinFiles(patterns []string) error {
+	if len(patterns) == 0 {
+		return fmt.Errorf("usage: /pin <glob>")
+	}
+
+	tm, err := shellToolManager()
+	if err != nil {
+		return err
+	}
+
+	for _, pattern := range patterns {
+		files, err := tm.ResolvePins([]string{pattern})
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			color.Yellow("No files match %s (ignored and oversized files are skipped)", pattern)
+			continue
+		}
+		if containsString(pinnedPatterns, pattern) {
+			continue
+		}
+
+		all, err := tm.ResolvePins(append(append([]string{}, pinnedPatterns...), pattern))
+		if err != nil {
+			return err
+		}
+		if total := tools.PinnedSize(all); total > tools.MaxPinnedSize {
+			return fmt.Errorf("pinning %s would bring pinned content to %d bytes (max %d)", pattern, total, tools.MaxPinnedSize)
+		}
+
+		pinnedPatterns = append(pinnedPatterns, pattern)
+		fmt.Printf("Pinned %s (%d files, %d bytes)\n", pattern, len(files), tools.PinnedSize(files))
+	}
+	return nil
+}
+
+func unpinFiles(patterns []string) error {
+	if len(patterns) == 0 {
+		pinnedPatterns = nil
+		fmt.Println("Removed all pins")
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		if !containsString(pinnedPatterns, pattern) {
+			color.Yellow("%s is not pinned", pattern)
+			continue
+		}
+		pinnedPatterns = removeString(pinnedPatterns, pattern)
+		fmt.Printf("Unpinned %s\n", pattern)
+	}
+	return nil
+}
+
+func listPins() error {
+	if len(pinnedPatterns) == 0 {
+		fmt.Println("No files pinned. Use /pin <glob> to add some.")
+		return nil
+	}
+
+	tm, err := shellToolManager()
+	if err != nil {
+		return err
+	}
+
+	files, err := tm.ResolvePins(pinnedPatterns)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Patterns: %s\n", strings.Join(pinnedPatterns, ", "))
+	for _, f := range files {
+		fmt.Printf("  %s (%d bytes)\n", f.Path, f.Size)
+	}
+	fmt.Printf("Total: %d files, %d / %d bytes\n", len(files), tools.PinnedSize(files), tools.MaxPinnedSize)
+	return nil
+}
+
+func shellToolManager() (*tools.ToolManager, error) {
+	cfg, err := loadConfig(false)
+	if err != nil {
+		return nil, err
+	}
+	tm := tools.NewToolManager(cfg.WorkDir, cfg.Verbose)
+	tm.SetPolicy(toolPolicy(cfg))
+	return tm, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	var out []string
+	for _, item := range list {
+		if item != s {
+			out = append(out, item)
+		}
+	}
+	return out
+}
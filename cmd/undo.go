@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/audit"
+	"github.com/brandnova/nova-horizon-cli/internal/snapshot"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	undoSession string
+	undoStep    int
+	undoList    bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Roll back file changes made by the agent",
+	Long: `Restore files changed by an agent session to their previous content and delete
+files the session created. Without --session the most recent session in the working
+directory is undone; --step n only rolls back step n and the steps after it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(false)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if undoList {
+			return listUndoSessions(cfg.WorkDir)
+		}
+		return undoChanges(cfg.WorkDir, undoSession, undoStep)
+	},
+}
+
+func init() {
+	undoCmd.Flags().StringVar(&undoSession, "session", "", "Session to undo (a prefix is enough; default: the most recent)")
+	undoCmd.Flags().IntVar(&undoStep, "step", 1, "Undo this step and every step after it")
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List sessions whose changes can be undone")
+	rootCmd.AddCommand(undoCmd)
+}
+
+// undoShell implements /undo [step] for the most recent session
+func undoShell(args []string) error {
+	step := 1
+	if len(args) > 1 {
+		return fmt.Errorf("usage: /undo [step]")
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid step %q", args[0])
+		}
+		step = n
+	}
+
+	cfg, err := loadConfig(false)
+	if err != nil {
+		return err
+	}
+	return undoChanges(cfg.WorkDir, "", step)
+}
+
+// undoChanges rolls back a session's changes in workDir from step onwards
+func undoChanges(workDir string, session string, step int) error {
+	if step < 1 {
+		return fmt.Errorf("step must be at least 1")
+	}
+
+	manifest, err := findUndoSession(workDir, session)
+	if err != nil {
+		return err
+	}
+	store, err := snapshot.Load(manifest.Session)
+	if err != nil {
+		return err
+	}
+
+	restored, err := store.Undo(step)
+	for _, r := range restored {
+		if r.Deleted {
+			color.Yellow("Deleted %s", r.Path)
+		} else {
+			color.Green("Restored %s", r.Path)
+		}
+	}
+	recordUndo(workDir, manifest.Session, step, restored, err)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Undid %d file(s) from step %d of session %s\n", len(restored), step, manifest.Session)
+	return nil
+}
+
+// findUndoSession picks the session to undo: the newest one, or the one matching a prefix
+func findUndoSession(workDir string, session string) (snapshot.Manifest, error) {
+	manifests, err := snapshot.Sessions(workDir)
+	if err != nil {
+		return snapshot.Manifest{}, err
+	}
+	if len(manifests) == 0 {
+		return snapshot.Manifest{}, fmt.Errorf("no agent changes to undo in %s", workDir)
+	}
+	if session == "" {
+		return manifests[0], nil
+	}
+
+	var matches []snapshot.Manifest
+	for _, m := range manifests {
+		if strings.HasPrefix(m.Session, session) {
+			matches = append(matches, m)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return snapshot.Manifest{}, fmt.Errorf("no changes to undo for session %s in %s", session, workDir)
+	case 1:
+		return matches[0], nil
+	default:
+		return snapshot.Manifest{}, fmt.Errorf("session %s is ambiguous (%d matches)", session, len(matches))
+	}
+}
+
+// recordUndo notes the rollback in the audit log next to the session's own entries
+func recordUndo(workDir string, session string, step int, restored []snapshot.Restored, undoErr error) {
+	entry := audit.Entry{
+		Tool:     "undo",
+		Step:     step,
+		Approval: "user",
+	}
+	for _, r := range restored {
+		entry.Paths = append(entry.Paths, r.Path)
+	}
+	if undoErr != nil {
+		entry.Error = undoErr.Error()
+	}
+	if err := audit.Open(workDir, session).Record(entry); err != nil {
+		slog.Warn("failed to write audit log", "error", err)
+	}
+}
+
+func listUndoSessions(workDir string) error {
+	manifests, err := snapshot.Sessions(workDir)
+	if err != nil {
+		return err
+	}
+	if len(manifests) == 0 {
+		fmt.Println("No agent changes to undo.")
+		return nil
+	}
+
+	for _, m := range manifests {
+		fmt.Printf("%s  %s\n", color.CyanString(m.Session), m.Started.Local().Format("2006-01-02 15:04:05"))
+		for _, c := range m.Changes {
+			action := "changed"
+			if !c.Existed {
+				action = "created"
+			}
+			fmt.Printf("  step %d  %s (%s)\n", c.Step, c.Path, action)
+		}
+	}
+	return nil
+}
@@ -2,23 +2,74 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/brandnova/nova-horizon-cli/internal/audit"
 	"github.com/brandnova/nova-horizon-cli/internal/gemini"
+	"github.com/brandnova/nova-horizon-cli/internal/git"
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
+	"github.com/brandnova/nova-horizon-cli/internal/permissions"
+	"github.com/brandnova/nova-horizon-cli/internal/snapshot"
 	"github.com/brandnova/nova-horizon-cli/internal/tools"
 	"github.com/fatih/color"
 	"github.com/google/generative-ai-go/genai"
 )
 
 type Config struct {
-	APIKey    string
-	Model     string
-	WorkDir   string
-	Verbose   bool
-	DryRun    bool
-	MaxSteps  int
-	AllowRun  bool
-	ApplyDiff bool
+	APIKey     string
+	Model      string
+	WorkDir    string
+	Verbose    bool
+	DryRun     bool
+	MaxSteps   int
+	MaxRetries int
+	AllowRun   bool
+	ApplyDiff  bool
+
+	// ContextTokenLimit is the conversation size (in tokens) above which older
+	// history is truncated and summarized. Zero disables context management.
+	ContextTokenLimit int
+
+	// SystemPrompt replaces the built-in system prompt template; SystemPromptAppend extends it.
+	// Both are Go text/templates rendered with gemini.PromptData.
+	SystemPrompt       string
+	SystemPromptAppend string
+
+	// Instructions are user/project instructions (e.g. NOVA.md) merged into the system prompt
+	Instructions string
+
+	// PinnedFiles are glob patterns whose files are injected into every request
+	PinnedFiles []string
+
+	// ToolPolicy holds the limits applied by the tools
+	ToolPolicy tools.Policy
+
+	// Git controls the dirty-tree guard and checkpoint commits
+	Git GitOptions
+
+	// ReadOnly ("ask" mode) withholds every tool that changes files or runs programs
+	// and tunes the system prompt for explaining code
+	ReadOnly bool
+
+	// Plan makes the agent investigate with read-only tools and propose a plan,
+	// which ReviewPlan must approve before anything is changed
+	Plan       bool
+	ReviewPlan PlanReviewer
+
+	// PatchFile, in a dry run, receives the simulated changes as a patch for git apply
+	PatchFile string
+
+	// Permissions decides which tool calls may run; calls its rules mark "ask" go to
+	// AskPermission, and are refused when it is nil. NewAgent loads the project's rules
+	// when Permissions is nil.
+	Permissions   *permissions.Engine
+	AskPermission PermissionPrompt
 }
 
 type Agent struct {
@@ -26,83 +77,243 @@ type Agent struct {
 	client    *gemini.GeminiClient
 	toolMgr   *tools.ToolManager
 	seenCalls map[string]bool
+	session   string
+	audit     *audit.Log
+	snapshots *snapshot.Store
+	repo      *git.Repo
+	prompt    string
+	step      int
+
+	// readOnlyReason, when set, withholds mutating tools and explains why calls to them are rejected
+	readOnlyReason string
+
+	// plan is the approved plan being executed (see plan.go)
+	plan string
+
+	// confirmation records how the current tool call was approved or refused, for the audit log
+	confirmation string
+
+	// Context window bookkeeping (see history.go)
+	pinnedPrompt []genai.Part
+	summary      string
+	usedTokens   int
+	usedLen      int
 }
 
 func NewAgent(cfg *Config) *Agent {
+	// The agent must not be able to grant itself permissions
+	policy := cfg.ToolPolicy
+	policy.ProtectedPaths = append(append([]string{}, policy.ProtectedPaths...), filepath.ToSlash(permissions.RelPath()))
+
+	toolMgr := tools.NewToolManager(cfg.WorkDir, cfg.Verbose)
+	toolMgr.SetPolicy(policy)
+	if cfg.DryRun {
+		toolMgr.EnableOverlay()
+	}
+	session := audit.NewSessionID()
+
+	if cfg.Permissions == nil {
+		engine, err := permissions.Load(cfg.WorkDir)
+		if err != nil {
+			slog.Warn("ignoring project permission rules", "error", err)
+			engine = &permissions.Engine{}
+		}
+		cfg.Permissions = engine
+	}
+	cfg.Permissions.SetDefaults(permissions.Defaults(cfg.AllowRun, cfg.ApplyDiff))
+
+	snapshots, err := snapshot.New(session, cfg.WorkDir)
+	if err != nil {
+		slog.Warn("file changes cannot be undone", "error", err)
+	}
+
 	return &Agent{
 		config:    cfg,
-		toolMgr:   tools.NewToolManager(cfg.WorkDir, cfg.Verbose),
+		toolMgr:   toolMgr,
 		seenCalls: make(map[string]bool),
+		session:   session,
+		audit:     audit.Open(cfg.WorkDir, session),
+		snapshots: snapshots,
 	}
 }
 
-func (a *Agent) Run(prompt string) error {
-	var err error
-	a.client, err = gemini.NewGeminiClient(a.config.APIKey, a.config.Model)
+// SessionID identifies this agent's run in the audit log
+func (a *Agent) SessionID() string {
+	return a.session
+}
+
+// Run executes the agent loop for a prompt. Cancelling ctx stops the current
+// step (streaming, tool execution or subprocess) and returns ctx.Err().
+func (a *Agent) Run(ctx context.Context, prompt string) error {
+	systemPrompt, err := BuildSystemPrompt(a.config)
+	if err != nil {
+		return err
+	}
+
+	a.prompt = prompt
+	if err := a.prepareGit(ctx); err != nil {
+		return err
+	}
+
+	a.client, err = gemini.NewGeminiClient(ctx, a.config.APIKey, a.config.Model)
 	if err != nil {
 		return err
 	}
 	defer a.client.Close()
 
-	ctx := context.Background()
+	retry := gemini.DefaultRetryPolicy()
+	retry.MaxAttempts = a.config.MaxRetries + 1
+	retry.OnRetry = func(attempt int, wait time.Duration, err error) {
+		color.Yellow("API request failed: %s", logger.Mask(err.Error()))
+		color.Yellow("Retrying in %s (retry %d/%d)...", wait.Round(100*time.Millisecond), attempt, a.config.MaxRetries)
+	}
+	a.client.SetRetryPolicy(retry)
+	a.client.SetSystemPrompt(systemPrompt)
 
 	// Initialize messages with user prompt
+	a.pinnedPrompt = []genai.Part{genai.Text(prompt)}
 	messages := []*genai.Content{
 		{
-			Role: "user",
-			Parts: []genai.Part{
-				genai.Text(prompt),
-			},
+			Role:  "user",
+			Parts: a.pinnedPrompt,
 		},
 	}
 
+	slog.Info("agent run started", "session", a.session, "model", a.config.Model, "workdir", a.config.WorkDir, "max_steps", a.config.MaxSteps, "dry_run", a.config.DryRun, "allow_run", a.config.AllowRun, "plan", a.config.Plan, "read_only", a.config.ReadOnly)
+
+	if a.config.ReadOnly {
+		a.readOnlyReason = "in read-only mode; describe the change to the user instead"
+	} else if a.config.Plan {
+		var approved bool
+		messages, approved, err = a.planFirst(ctx, messages)
+		if err != nil || !approved {
+			return err
+		}
+	}
+
+	_, _, _, err = a.loop(ctx, messages)
+	if a.config.DryRun {
+		if reportErr := a.reportDryRun(); err == nil {
+			err = reportErr
+		}
+	}
+	return err
+}
+
+// loop runs agent steps until the model answers without calling a tool. It returns
+// the conversation, the model's final reply and whether the model finished normally
+// (rather than being blocked, looping or running out of steps).
+func (a *Agent) loop(ctx context.Context, messages []*genai.Content) ([]*genai.Content, string, bool, error) {
+	var err error
+	var reply strings.Builder
+
 	for step := 0; step < a.config.MaxSteps; step++ {
+		a.step++
+		slog.Debug("agent step", "step", a.step, "messages", len(messages))
 		if a.config.Verbose {
 			fmt.Printf("[Step %d/%d]\n", step+1, a.config.MaxSteps)
 		}
 
-		// Build tools
-		toolDefs := gemini.BuildTools()
+		// Keep the conversation within the context window
+		messages, err = a.manageContext(ctx, messages)
+		if err != nil {
+			return messages, "", false, err
+		}
 
-		// Call Gemini API
-		resp, err := a.client.GenerateContent(ctx, messages, toolDefs)
+		// Build tools
+		toolDefs := gemini.BuildTools(a.readOnlyReason != "")
+
+		// Call Gemini API, rendering text as it streams in
+		streamed := false
+		var stepText strings.Builder
+		resp, err := a.client.GenerateContentStream(ctx, a.withPins(messages), toolDefs, func(text string) {
+			streamed = true
+			stepText.WriteString(text)
+			fmt.Print(text)
+		})
+
+		if streamed {
+			fmt.Println()
+		}
+		if ctx.Err() != nil {
+			return messages, "", false, ctx.Err()
+		}
+		var blocked *genai.BlockedError
+		if errors.As(err, &blocked) {
+			reportBlocked(blocked)
+			return messages, "", false, nil
+		}
 		if err != nil {
-			return fmt.Errorf("API call failed: %w", err)
+			return messages, "", false, fmt.Errorf("API call failed: %w", err)
 		}
 
 		if resp == nil || len(resp.Candidates) == 0 {
-			return fmt.Errorf("empty response from API")
+			if resp != nil && resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+				reportBlocked(&genai.BlockedError{PromptFeedback: resp.PromptFeedback})
+				return messages, "", false, nil
+			}
+			return messages, "", false, fmt.Errorf("empty response from API")
 		}
 
 		candidate := resp.Candidates[0]
 		if candidate.Content == nil {
-			return fmt.Errorf("malformed response")
+			if candidate.FinishReason != genai.FinishReasonStop && candidate.FinishReason != genai.FinishReasonUnspecified {
+				color.Yellow("Model returned no content: %s.", describeFinishReason(candidate.FinishReason))
+				return messages, "", false, nil
+			}
+			return messages, "", false, fmt.Errorf("malformed response")
 		}
 
 		// Add response to messages
 		messages = append(messages, candidate.Content)
+		a.recordUsage(resp.UsageMetadata, len(messages))
 
 		// Check for function calls
 		hasFunctionCall := false
 		var functionResponses []genai.Part
+		var succeeded []genai.FunctionCall
 
 		for _, part := range candidate.Content.Parts {
 			if fc, ok := part.(genai.FunctionCall); ok {
 				hasFunctionCall = true
 
+				// Don't start new work once the step has been cancelled
+				if ctx.Err() != nil {
+					return messages, "", false, ctx.Err()
+				}
+
 				// Check for loops
 				callSignature := fmt.Sprintf("%s:%v", fc.Name, fc.Args)
 				if a.seenCalls[callSignature] {
+					slog.Info("aborting on repeated function call", "tool", fc.Name)
 					color.Yellow("Model is looping on the same function call. Aborting.")
-					return nil
+					return messages, "", false, nil
 				}
 				a.seenCalls[callSignature] = true
 
 				// Execute function
-				result, err := a.executeFunction(fc)
+				paths := auditPaths(fc)
+				var before map[string]string
+				if hashedTools[fc.Name] {
+					before = a.hashPaths(paths)
+				}
+				a.confirmation = ""
+				deviation := a.checkPlan(fc, paths)
+				start := time.Now()
+				result, err := a.executeFunction(ctx, fc)
+				logToolCall(fc, time.Since(start), err)
+				a.recordAudit(a.step, fc, paths, before, deviation, err)
+				if ctx.Err() != nil {
+					return messages, "", false, ctx.Err()
+				}
 				if err != nil {
 					color.Red("Error executing %s: %v", fc.Name, err)
 					result = fmt.Sprintf("Error: %v", err)
+				} else if hashedTools[fc.Name] {
+					succeeded = append(succeeded, fc)
+				}
+				if deviation {
+					result += "\n" + deviationNote
 				}
 
 				if a.config.Verbose {
@@ -116,35 +327,85 @@ func (a *Agent) Run(prompt string) error {
 					Name:     fc.Name,
 					Response: map[string]interface{}{"result": result},
 				})
-			} else if text, ok := part.(genai.Text); ok {
-				// Final response
-				if string(text) != "" {
-					fmt.Println(string(text))
-				}
 			}
 		}
 
+		// Checkpoint the step's changes before the model builds on them
+		a.checkpoint(ctx, succeeded)
+
 		// If we have function responses, add them to history
 		if len(functionResponses) > 0 {
 			messages = append(messages, &genai.Content{
-				Role: "function", // genai uses 'function' role for tool outputs? Or 'tool'?
-				// genai.Client usually expects 'function' or 'user' depending on API version but docs say 'function' for responses
-				// Wait, the SDK constants say RoleFunction = "function"
+				Role:  "function", // The Gemini API's role for function responses
 				Parts: functionResponses,
 			})
 		}
 
-		// If no function calls, we're done
-		if !hasFunctionCall {
-			return nil
+		// If no function calls, we're done unless the reply was truncated
+		if hasFunctionCall {
+			reply.Reset()
+			continue
+		}
+		reply.WriteString(stepText.String())
+		switch candidate.FinishReason {
+		case genai.FinishReasonStop, genai.FinishReasonUnspecified:
+			return messages, reply.String(), true, nil
+		case genai.FinishReasonMaxTokens:
+			color.Yellow("Response truncated at the output token limit, asking the model to continue...")
+			messages = append(messages, &genai.Content{
+				Role:  "user",
+				Parts: []genai.Part{genai.Text(continuePrompt)},
+			})
+		default:
+			color.Yellow("Response stopped: %s.", describeFinishReason(candidate.FinishReason))
+			return messages, "", false, nil
 		}
 	}
 
+	slog.Info("agent reached maximum steps", "max_steps", a.config.MaxSteps)
 	color.Yellow("Reached maximum steps (%d)", a.config.MaxSteps)
+	return messages, "", false, nil
+}
+
+// snapshot saves the current content of filePath so the step can be undone
+func (a *Agent) snapshot(filePath string) error {
+	if a.snapshots == nil || a.config.DryRun {
+		return nil
+	}
+	relPath, err := a.toolMgr.RelPath(filePath)
+	if err != nil {
+		// Let the tool report invalid paths
+		return nil
+	}
+	if err := a.snapshots.Save(a.step, relPath); err != nil {
+		return fmt.Errorf("failed to snapshot %s for undo: %w", filePath, err)
+	}
 	return nil
 }
 
-func (a *Agent) executeFunction(fc genai.FunctionCall) (string, error) {
+// logToolCall records a tool invocation; long arguments such as file contents are truncated
+func logToolCall(fc genai.FunctionCall, duration time.Duration, err error) {
+	args, _ := json.Marshal(fc.Args)
+	attrs := []any{"tool", fc.Name, "args", logger.Truncate(string(args), 300), "duration", duration}
+	if err != nil {
+		slog.Info("tool call failed", append(attrs, "error", err)...)
+		return
+	}
+	slog.Info("tool call", attrs...)
+}
+
+func (a *Agent) executeFunction(ctx context.Context, fc genai.FunctionCall) (string, error) {
+	if a.readOnlyReason != "" && gemini.MutatingTools[fc.Name] {
+		a.confirmation = "plan"
+		if a.config.ReadOnly {
+			a.confirmation = "read-only"
+		}
+		return "", fmt.Errorf("%s is not available %s", fc.Name, a.readOnlyReason)
+	}
+	if err := a.authorize(fc); err != nil {
+		return "", err
+	}
+
 	switch fc.Name {
 	case "get_files_info":
 		dir, _ := fc.Args["directory"].(string)
@@ -153,6 +414,26 @@ func (a *Agent) executeFunction(fc genai.FunctionCall) (string, error) {
 		}
 		return a.toolMgr.GetFilesInfo(dir)
 
+	case "git_status":
+		return a.toolMgr.GitStatus(ctx)
+
+	case "git_diff":
+		path, _ := fc.Args["path"].(string)
+		ref, _ := fc.Args["ref"].(string)
+		staged, _ := fc.Args["staged"].(bool)
+		return a.toolMgr.GitDiff(ctx, path, ref, staged)
+
+	case "git_log":
+		path, _ := fc.Args["path"].(string)
+		return a.toolMgr.GitLog(ctx, path, intArg(fc.Args, "max_count"))
+
+	case "git_blame":
+		filePath, ok := fc.Args["file_path"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing file_path argument")
+		}
+		return a.toolMgr.GitBlame(ctx, filePath, intArg(fc.Args, "start_line"), intArg(fc.Args, "end_line"))
+
 	case "get_file_content":
 		filePath, ok := fc.Args["file_path"].(string)
 		if !ok {
@@ -170,20 +451,59 @@ func (a *Agent) executeFunction(fc genai.FunctionCall) (string, error) {
 			return "", fmt.Errorf("missing content argument")
 		}
 
-		if a.config.DryRun {
-			return fmt.Sprintf("[DRY RUN] Would write %d bytes to %s", len(content), filePath), nil
+		if err := a.snapshot(filePath); err != nil {
+			return "", err
 		}
 
 		return a.toolMgr.WriteFile(filePath, content)
 
-	case "run_file":
+	case "delete_file":
 		filePath, ok := fc.Args["file_path"].(string)
 		if !ok {
 			return "", fmt.Errorf("missing file_path argument")
 		}
+		if err := a.snapshot(filePath); err != nil {
+			return "", err
+		}
+		return a.toolMgr.DeleteFile(filePath)
+
+	case "move_file":
+		source, ok := fc.Args["source_path"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing source_path argument")
+		}
+		dest, ok := fc.Args["destination_path"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing destination_path argument")
+		}
+
+		if err := a.snapshot(source); err != nil {
+			return "", err
+		}
+		if err := a.snapshot(dest); err != nil {
+			return "", err
+		}
+		return a.toolMgr.MoveFile(source, dest)
+
+	case "create_directory":
+		dir, ok := fc.Args["directory"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing directory argument")
+		}
 
-		if !a.config.AllowRun {
-			return "", fmt.Errorf("program execution not allowed (use --allow-run flag)")
+		if err := a.snapshot(dir); err != nil {
+			return "", err
+		}
+		return a.toolMgr.CreateDirectory(dir)
+
+	case "run_file":
+		filePath, ok := fc.Args["file_path"].(string)
+		if !ok {
+			return "", fmt.Errorf("missing file_path argument")
+		}
+		// A program would see the real files, not the simulated ones, and could change them
+		if a.config.DryRun {
+			return fmt.Sprintf("[DRY RUN] %s was not run: programs are not executed in a dry run, so there is no output. Continue without it and tell the user what you expect it to do.", commandLine(fc)), nil
 		}
 
 		args := []string{}
@@ -195,9 +515,23 @@ func (a *Agent) executeFunction(fc genai.FunctionCall) (string, error) {
 			}
 		}
 
-		return a.toolMgr.RunFile(filePath, args)
+		return a.toolMgr.RunFile(ctx, filePath, args)
 
 	default:
 		return "", fmt.Errorf("unknown function: %s", fc.Name)
 	}
 }
+
+// intArg reads an optional integer argument; JSON numbers arrive as float64
+func intArg(args map[string]interface{}, name string) int {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/brandnova/nova-horizon-cli/internal/audit"
+	"github.com/brandnova/nova-horizon-cli/internal/gemini"
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// maxAuditArg is the longest string argument stored verbatim; file contents are
+// covered by the content hashes instead
+const maxAuditArg = 1000
+
+// hashedTools are the tools whose affected files are hashed before and after the call
+var hashedTools = gemini.MutatingTools
+
+// auditPaths returns the workdir-relative paths a tool call names
+func auditPaths(fc genai.FunctionCall) []string {
+	var paths []string
+	for _, key := range []string{"file_path", "source_path", "destination_path", "directory", "path"} {
+		if p, ok := fc.Args[key].(string); ok && p != "" {
+			paths = append(paths, filepath.ToSlash(filepath.Clean(p)))
+		}
+	}
+	return paths
+}
+
+// hashPaths records the content hash of each path; paths that cannot be hashed are left out
+func (a *Agent) hashPaths(paths []string) map[string]string {
+	hashes := make(map[string]string, len(paths))
+	for _, p := range paths {
+		h, err := a.toolMgr.HashFile(p)
+		if err != nil {
+			continue
+		}
+		hashes[p] = h
+	}
+	return hashes
+}
+
+// auditArgs copies tool arguments for the log, masking secrets and shortening long strings
+func auditArgs(args map[string]any) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		if s, ok := v.(string); ok {
+			if len(s) > maxAuditArg {
+				v = fmt.Sprintf("<%d bytes>", len(s))
+			} else {
+				v = logger.Mask(s)
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// recordAudit appends a tool call to the workdir's audit log. Failing to write the
+// log is reported but does not stop the agent.
+func (a *Agent) recordAudit(step int, fc genai.FunctionCall, paths []string, before map[string]string, deviation bool, callErr error) {
+	entry := audit.Entry{
+		Step:          step,
+		Tool:          fc.Name,
+		Args:          auditArgs(fc.Args),
+		Paths:         paths,
+		Approval:      a.confirmation,
+		DryRun:        a.config.DryRun,
+		PlanDeviation: deviation,
+	}
+	if hashedTools[fc.Name] {
+		entry.Before = before
+		entry.After = a.hashPaths(paths)
+	}
+	if fc.Name == "run_file" {
+		var exitErr *exec.ExitError
+		switch {
+		case callErr == nil:
+			code := 0
+			entry.ExitCode = &code
+		case errors.As(callErr, &exitErr):
+			code := exitErr.ExitCode()
+			entry.ExitCode = &code
+		}
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+
+	if err := a.audit.Record(entry); err != nil {
+		slog.Warn("failed to write audit log", "error", err)
+	}
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/audit"
+	"github.com/brandnova/nova-horizon-cli/internal/git"
+	"github.com/brandnova/nova-horizon-cli/internal/permissions"
+	"github.com/fatih/color"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// GitOptions controls git-aware mode, used when the working directory is in a git repository
+type GitOptions struct {
+	Enabled    bool
+	AllowDirty bool   // Start even with uncommitted changes
+	AutoCommit bool   // Commit after every step that changed files
+	Branch     string // Branch to switch to (or create) before starting
+}
+
+// maxDirtyListed is how many uncommitted files the dirty-tree error names
+const maxDirtyListed = 5
+
+// prepareGit checks the working tree and switches branch before the agent runs.
+// Dry runs and read-only runs change nothing, so they skip git entirely.
+func (a *Agent) prepareGit(ctx context.Context) error {
+	opts := a.config.Git
+	if !opts.Enabled || a.config.DryRun || a.config.ReadOnly {
+		return nil
+	}
+
+	repo, err := git.Open(ctx, a.config.WorkDir)
+	if err != nil || repo == nil {
+		return err
+	}
+	repo.Exclude(filepath.ToSlash(audit.RelPath()), filepath.ToSlash(permissions.RelPath()))
+
+	if !opts.AllowDirty {
+		dirty, err := repo.Dirty(ctx)
+		if err != nil {
+			return err
+		}
+		if len(dirty) > 0 {
+			listed := dirty
+			if len(listed) > maxDirtyListed {
+				listed = append(listed[:maxDirtyListed:maxDirtyListed], fmt.Sprintf("... and %d more", len(dirty)-maxDirtyListed))
+			}
+			return fmt.Errorf("working tree has uncommitted changes; commit or stash them, or use --allow-dirty:\n  %s", strings.Join(listed, "\n  "))
+		}
+	}
+
+	if opts.Branch != "" {
+		current, err := repo.CurrentBranch(ctx)
+		if err != nil {
+			return err
+		}
+		if current != opts.Branch {
+			if err := repo.Checkout(ctx, opts.Branch); err != nil {
+				return err
+			}
+			color.Cyan("Switched to branch %s", opts.Branch)
+		}
+	}
+
+	if opts.AutoCommit {
+		a.repo = repo
+	}
+	return nil
+}
+
+// checkpoint commits the files changed by a step's successful tool calls. Only those
+// paths are staged, so the user's own uncommitted work stays out of the commit.
+// Files changed by programs the agent ran are not tracked and not committed.
+func (a *Agent) checkpoint(ctx context.Context, calls []genai.FunctionCall) {
+	if a.repo == nil || len(calls) == 0 {
+		return
+	}
+
+	var paths []string
+	for _, fc := range calls {
+		if fc.Name == "run_file" {
+			continue
+		}
+		for _, p := range auditPaths(fc) {
+			paths = appendUnique(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	subject := commitSubject(calls)
+	message := fmt.Sprintf("%s\n\nPrompt: %s\n\nNova-Session: %s\nNova-Step: %d\n", subject, firstLine(a.prompt, 200), a.session, a.step)
+
+	hash, err := a.repo.CommitPaths(ctx, message, paths)
+	if err != nil {
+		slog.Warn("checkpoint commit failed", "step", a.step, "error", err)
+		color.Yellow("Could not commit step %d: %v", a.step, err)
+		return
+	}
+	if hash == "" {
+		return
+	}
+	slog.Info("checkpoint commit", "step", a.step, "commit", hash)
+	fmt.Printf(" - Committed %s: %s\n", hash, subject)
+}
+
+// commitSubject summarizes a step's tool calls, e.g. "nova: write main.go, util.go"
+func commitSubject(calls []genai.FunctionCall) string {
+	var written, deleted, moved, created []string
+	for _, fc := range calls {
+		path, _ := fc.Args["file_path"].(string)
+		switch fc.Name {
+		case "write_file":
+			written = appendUnique(written, path)
+		case "delete_file":
+			deleted = appendUnique(deleted, path)
+		case "move_file":
+			source, _ := fc.Args["source_path"].(string)
+			dest, _ := fc.Args["destination_path"].(string)
+			moved = appendUnique(moved, source+" -> "+dest)
+		case "create_directory":
+			dir, _ := fc.Args["directory"].(string)
+			created = appendUnique(created, dir)
+		}
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		verb  string
+		names []string
+	}{
+		{"write", written},
+		{"delete", deleted},
+		{"move", moved},
+		{"create", created},
+	} {
+		if len(group.names) > 0 {
+			parts = append(parts, group.verb+" "+listNames(group.names))
+		}
+	}
+	return "nova: " + strings.Join(parts, "; ")
+}
+
+// listNames joins up to three names and counts the rest
+func listNames(names []string) string {
+	if len(names) <= 3 {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:3], ", "), len(names)-3)
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// firstLine returns the first line of s, cut to max bytes
+func firstLine(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	if len(s) > max {
+		s = s[:max] + "..."
+	}
+	return s
+}
@@ -0,0 +1,52 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/fatih/color"
+)
+
+// reportDryRun prints everything the dry run would have changed as one diff, and
+// writes it to Config.PatchFile when set
+func (a *Agent) reportDryRun() error {
+	changes, dirs := a.toolMgr.Changes()
+	slog.Info("dry run finished", "session", a.session, "files", len(changes), "directories", len(dirs))
+
+	fmt.Println()
+	if len(changes) == 0 && len(dirs) == 0 {
+		color.Cyan("Dry run: no files would change.")
+	} else {
+		color.New(color.FgCyan, color.Bold).Printf("Dry run: %d file(s) would change\n", len(changes))
+		for _, c := range changes {
+			action := "modify"
+			switch {
+			case !c.OldExists:
+				action = "create"
+			case !c.NewExists:
+				action = "delete"
+			}
+			fmt.Printf("  %-6s %s\n", action, c.Path)
+		}
+		for _, dir := range dirs {
+			fmt.Printf("  mkdir  %s/\n", dir)
+		}
+	}
+
+	patch := tools.FormatPatch(changes)
+	if patch != "" {
+		fmt.Println()
+		tools.PrintColoredDiff(patch)
+	}
+
+	if a.config.PatchFile == "" {
+		return nil
+	}
+	if err := os.WriteFile(a.config.PatchFile, []byte(patch), 0644); err != nil {
+		return fmt.Errorf("failed to write patch: %w", err)
+	}
+	fmt.Printf("\nPatch written to %s (apply it with: git apply %s)\n", a.config.PatchFile, a.config.PatchFile)
+	return nil
+}
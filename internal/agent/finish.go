@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// continuePrompt is sent when a reply is cut off by the output token limit
+const continuePrompt = "Your previous response was cut off because it hit the output token limit. Continue exactly where you left off, without repeating anything."
+
+// describeFinishReason returns a user-facing explanation for a finish reason other than STOP
+func describeFinishReason(reason genai.FinishReason) string {
+	switch reason {
+	case genai.FinishReasonMaxTokens:
+		return "the response hit the maximum output token limit"
+	case genai.FinishReasonSafety:
+		return "the response was blocked by safety filters"
+	case genai.FinishReasonRecitation:
+		return "the response was blocked because it recited copyrighted or training material"
+	case genai.FinishReasonOther:
+		return "the model stopped for an unspecified reason"
+	default:
+		return fmt.Sprintf("the model stopped unexpectedly (%s)", reason)
+	}
+}
+
+// reportBlocked prints why a prompt or response was blocked, including the safety ratings that triggered it
+func reportBlocked(blocked *genai.BlockedError) {
+	slog.Info("response blocked", "reason", blocked.Error())
+	if blocked.PromptFeedback != nil && blocked.PromptFeedback.BlockReason != genai.BlockReasonUnspecified {
+		color.Red("Prompt blocked by Gemini (%s). Try rephrasing the request.", blocked.PromptFeedback.BlockReason)
+		printSafetyRatings(blocked.PromptFeedback.SafetyRatings)
+	}
+	if blocked.Candidate != nil {
+		color.Red("Response stopped: %s.", describeFinishReason(blocked.Candidate.FinishReason))
+		printSafetyRatings(blocked.Candidate.SafetyRatings)
+	}
+}
+
+func printSafetyRatings(ratings []*genai.SafetyRating) {
+	for _, r := range ratings {
+		if r == nil || (!r.Blocked && r.Probability < genai.HarmProbabilityMedium) {
+			continue
+		}
+		line := fmt.Sprintf("  - %s: %s", trimEnumPrefix(r.Category.String(), "HarmCategory"), trimEnumPrefix(r.Probability.String(), "HarmProbability"))
+		if r.Blocked {
+			line += " (blocked)"
+		}
+		fmt.Println(line)
+	}
+}
+
+// trimEnumPrefix turns e.g. "HarmCategoryDangerousContent" into "DangerousContent"
+func trimEnumPrefix(s, prefix string) string {
+	return strings.TrimPrefix(s, prefix)
+}
@@ -0,0 +1,26 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestDescribeFinishReason(t *testing.T) {
+	tests := []struct {
+		reason genai.FinishReason
+		want   string
+	}{
+		{genai.FinishReasonMaxTokens, "maximum output token limit"},
+		{genai.FinishReasonSafety, "safety filters"},
+		{genai.FinishReasonRecitation, "recited"},
+		{genai.FinishReasonOther, "unspecified reason"},
+		{genai.FinishReasonUnspecified, "unexpectedly"},
+	}
+	for _, tt := range tests {
+		if got := describeFinishReason(tt.reason); !strings.Contains(got, tt.want) {
+			t.Errorf("describeFinishReason(%v) = %q, want it to mention %q", tt.reason, got, tt.want)
+		}
+	}
+}
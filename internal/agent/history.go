@@ -0,0 +1,201 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/fatih/color"
+	"github.com/google/generative-ai-go/genai"
+)
+
+const (
+	charsPerToken        = 4    // Rough estimate used when the API hasn't reported usage yet
+	keepRecentMessages   = 6    // Most recent messages are never truncated or summarized
+	truncatedOutputBytes = 1000 // How much of an old tool output survives truncation
+	transcriptOutputMax  = 2000 // How much of each tool output is shown to the summarizer
+)
+
+const summaryInstruction = `You compress the history of a coding agent session so it can keep working within its context window.
+
+Summarize the transcript you are given. Keep:
+- What the user asked for and any constraints they stated
+- Files that were read or written, and the important facts learned from them
+- Commands that were run and their outcomes (including errors)
+- Decisions made and work still remaining
+
+Be concise and factual. Write plain text, no preamble.`
+
+// contextSize returns the token count of messages, using the API's reported usage for the
+// part of the conversation it has seen and an estimate for anything appended since.
+func (a *Agent) contextSize(messages []*genai.Content) int {
+	if a.usedLen > 0 && a.usedLen <= len(messages) {
+		return a.usedTokens + estimateTokens(messages[a.usedLen:])
+	}
+	return estimateTokens(messages)
+}
+
+// recordUsage remembers the token count reported for a response covering messages[:n]
+func (a *Agent) recordUsage(usage *genai.UsageMetadata, n int) {
+	if usage == nil || usage.TotalTokenCount == 0 {
+		return
+	}
+	a.usedTokens = int(usage.TotalTokenCount)
+	a.usedLen = n
+}
+
+// manageContext keeps the conversation under the configured token limit. Old tool outputs
+// are truncated first; if that is not enough, older turns are summarized by the model.
+// The original prompt (messages[0]) is always preserved.
+func (a *Agent) manageContext(ctx context.Context, messages []*genai.Content) ([]*genai.Content, error) {
+	limit := a.config.ContextTokenLimit
+	if limit <= 0 || a.contextSize(messages) <= limit {
+		return messages, nil
+	}
+
+	cut := len(messages) - keepRecentMessages
+	if cut <= 1 {
+		return messages, nil
+	}
+
+	if n := truncateToolOutputs(messages[1:cut]); n > 0 {
+		slog.Info("truncated old tool outputs", "count", n, "limit", limit)
+		a.usedLen = 0
+		if a.config.Verbose {
+			color.Yellow("Context near limit: truncated %d old tool outputs", n)
+		}
+		if a.contextSize(messages) <= limit {
+			return messages, nil
+		}
+	}
+
+	// Summarize up to a model turn so the kept history still starts with a model reply
+	boundary := -1
+	for i := cut; i > 1; i-- {
+		if messages[i].Role == "model" {
+			boundary = i
+			break
+		}
+	}
+	if boundary < 0 {
+		return messages, nil
+	}
+
+	color.Yellow("Context near limit (~%d tokens): summarizing %d earlier messages...", a.contextSize(messages), boundary-1)
+
+	var transcript strings.Builder
+	if a.summary != "" {
+		transcript.WriteString("Summary of even earlier steps:\n")
+		transcript.WriteString(a.summary)
+		transcript.WriteString("\n\n")
+	}
+	writeTranscript(&transcript, messages[1:boundary])
+
+	summary, err := a.client.GenerateText(ctx, summaryInstruction, transcript.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+	a.summary = strings.TrimSpace(summary)
+
+	first := &genai.Content{
+		Role:  "user",
+		Parts: append(append([]genai.Part{}, a.pinnedPrompt...), genai.Text("Summary of earlier steps in this task:\n"+a.summary)),
+	}
+	compacted := append([]*genai.Content{first}, messages[boundary:]...)
+	slog.Info("summarized conversation history", "summarized_messages", boundary-1, "kept_messages", len(compacted), "summary_bytes", len(a.summary))
+	a.usedLen = 0
+	return compacted, nil
+}
+
+// estimateTokens approximates the token count of contents
+func estimateTokens(contents []*genai.Content) int {
+	chars := 0
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		for _, part := range c.Parts {
+			chars += partChars(part)
+		}
+	}
+	return chars / charsPerToken
+}
+
+func partChars(part genai.Part) int {
+	switch p := part.(type) {
+	case genai.Text:
+		return len(p)
+	case genai.FunctionCall:
+		args, _ := json.Marshal(p.Args)
+		return len(p.Name) + len(args)
+	case genai.FunctionResponse:
+		resp, _ := json.Marshal(p.Response)
+		return len(p.Name) + len(resp)
+	default:
+		return 0
+	}
+}
+
+// truncateToolOutputs shortens long function results in place and returns how many were cut
+func truncateToolOutputs(messages []*genai.Content) int {
+	count := 0
+	for i, c := range messages {
+		if c == nil {
+			continue
+		}
+		var parts []genai.Part
+		changed := false
+		for _, part := range c.Parts {
+			fr, ok := part.(genai.FunctionResponse)
+			if !ok {
+				parts = append(parts, part)
+				continue
+			}
+			result, _ := fr.Response["result"].(string)
+			if len(result) <= truncatedOutputBytes {
+				parts = append(parts, part)
+				continue
+			}
+			kept := tools.CutAtRune(result, truncatedOutputBytes)
+			parts = append(parts, genai.FunctionResponse{
+				Name: fr.Name,
+				Response: map[string]interface{}{
+					"result": fmt.Sprintf("%s\n[output truncated: %d of %d bytes omitted to save context]", kept, len(result)-len(kept), len(result)),
+				},
+			})
+			changed = true
+			count++
+		}
+		if changed {
+			messages[i] = &genai.Content{Role: c.Role, Parts: parts}
+		}
+	}
+	return count
+}
+
+// writeTranscript renders messages as plain text for the summarizer
+func writeTranscript(b *strings.Builder, messages []*genai.Content) {
+	for _, c := range messages {
+		if c == nil {
+			continue
+		}
+		for _, part := range c.Parts {
+			switch p := part.(type) {
+			case genai.Text:
+				fmt.Fprintf(b, "[%s] %s\n", c.Role, string(p))
+			case genai.FunctionCall:
+				args, _ := json.Marshal(p.Args)
+				fmt.Fprintf(b, "[%s] called %s(%s)\n", c.Role, p.Name, args)
+			case genai.FunctionResponse:
+				result := fmt.Sprint(p.Response["result"])
+				if len(result) > transcriptOutputMax {
+					result = tools.CutAtRune(result, transcriptOutputMax) + "\n[...]"
+				}
+				fmt.Fprintf(b, "[result of %s] %s\n", p.Name, result)
+			}
+		}
+	}
+}
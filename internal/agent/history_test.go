@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestTruncateToolOutputsKeepsValidUTF8(t *testing.T) {
+	// Place a multi-byte rune across the cut-off
+	output := strings.Repeat("a", truncatedOutputBytes-1) + strings.Repeat("é", 100)
+	messages := []*genai.Content{{
+		Role:  "function",
+		Parts: []genai.Part{genai.FunctionResponse{Name: "read_file", Response: map[string]any{"result": output}}},
+	}}
+
+	if n := truncateToolOutputs(messages); n != 1 {
+		t.Fatalf("truncated %d outputs, want 1", n)
+	}
+	result := messages[0].Parts[0].(genai.FunctionResponse).Response["result"].(string)
+	if !utf8.ValidString(result) {
+		t.Errorf("truncated output is not valid UTF-8: %q", result[truncatedOutputBytes-4:truncatedOutputBytes+4])
+	}
+	if want := "200 of 1199 bytes omitted"; !strings.Contains(result, want) {
+		t.Errorf("truncation note missing %q in %q", want, result[truncatedOutputBytes-1:])
+	}
+}
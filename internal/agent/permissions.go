@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/gemini"
+	"github.com/brandnova/nova-horizon-cli/internal/permissions"
+	"github.com/fatih/color"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// PermissionPrompt asks the user whether a tool call may go ahead. remember describes
+// the rule that "always" answers would add; when it is empty only "once" or "deny"
+// may be offered.
+type PermissionPrompt func(question string, remember string) permissions.Choice
+
+// authorize checks a tool call against the permission rules, asking the user when
+// they say so. Reads are allowed by default but deny and ask rules apply to them too.
+// The outcome is kept in a.confirmation for the audit log.
+func (a *Agent) authorize(fc genai.FunctionCall) error {
+	if a.config.Permissions == nil {
+		return nil
+	}
+
+	req := permissions.Request{Tool: fc.Name, Paths: auditPaths(fc)}
+	if fc.Name == "run_file" {
+		req.Command = commandLine(fc)
+	}
+
+	action, rule := a.config.Permissions.Check(req)
+	switch action {
+	case permissions.Deny:
+		a.confirmation = "denied (rule)"
+		if rule != nil {
+			return fmt.Errorf("permission denied by rule %q in %s", rule.String(), permissions.RelPath())
+		}
+		return fmt.Errorf("permission denied for %s", fc.Name)
+	case permissions.Allow:
+		a.confirmation = "auto"
+		if rule != nil {
+			a.confirmation = "rule"
+		}
+		return nil
+	}
+
+	// Dry runs simulate changes and skip programs, so only a deny rule stops them
+	if a.config.DryRun && gemini.MutatingTools[fc.Name] {
+		a.confirmation = "dry-run"
+		return nil
+	}
+
+	if a.config.AskPermission == nil {
+		a.confirmation = "denied (no terminal)"
+		return fmt.Errorf("%s needs the user's permission, but there is no terminal to ask", fc.Name)
+	}
+
+	// Calls that always ask, such as deletes, can't be allowed for good
+	var remember []permissions.Rule
+	var described []string
+	if !permissions.AlwaysAsk[fc.Name] {
+		remember = permissions.RulesFor(req)
+		for _, r := range remember {
+			described = append(described, r.String())
+		}
+	}
+
+	choice := a.config.AskPermission(a.describeCall(fc), strings.Join(described, ", "))
+	if remember == nil && choice != permissions.Refuse {
+		choice = permissions.Once
+	}
+	switch choice {
+	case permissions.Once:
+		a.confirmation = "user"
+	case permissions.Session:
+		a.confirmation = "user (session)"
+	case permissions.Project:
+		a.confirmation = "user (project)"
+	default:
+		a.confirmation = "denied"
+		return fmt.Errorf("the user denied permission for %s", fc.Name)
+	}
+
+	if err := a.config.Permissions.Remember(remember, choice); err != nil {
+		slog.Warn("failed to save permission rule", "error", err)
+		color.Yellow("Could not save the permission rule: %v", err)
+	}
+	return nil
+}
+
+// describeCall phrases a tool call as a question for the permission prompt
+func (a *Agent) describeCall(fc genai.FunctionCall) string {
+	path, _ := fc.Args["file_path"].(string)
+	switch fc.Name {
+	case "write_file":
+		content, _ := fc.Args["content"].(string)
+		return fmt.Sprintf("Write %d bytes to %s?", len(content), path)
+	case "delete_file":
+		if description, err := a.toolMgr.DescribeFile(path); err == nil {
+			path = description
+		}
+		return fmt.Sprintf("Delete %s?", path)
+	case "move_file":
+		source, _ := fc.Args["source_path"].(string)
+		dest, _ := fc.Args["destination_path"].(string)
+		return fmt.Sprintf("Move %s to %s?", source, dest)
+	case "create_directory":
+		dir, _ := fc.Args["directory"].(string)
+		return fmt.Sprintf("Create directory %s?", dir)
+	case "run_file":
+		return fmt.Sprintf("Run %s?", commandLine(fc))
+	case "get_file_content":
+		return fmt.Sprintf("Read %s?", path)
+	default:
+		if paths := auditPaths(fc); len(paths) > 0 {
+			return fmt.Sprintf("Call %s on %s?", fc.Name, strings.Join(paths, ", "))
+		}
+		return fmt.Sprintf("Call %s?", fc.Name)
+	}
+}
+
+// commandLine joins run_file's file and arguments, as matched by command rules
+func commandLine(fc genai.FunctionCall) string {
+	path, _ := fc.Args["file_path"].(string)
+	parts := []string{path}
+	if args, ok := fc.Args["args"].([]interface{}); ok {
+		for _, arg := range args {
+			if s, ok := arg.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,99 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandnova/nova-horizon-cli/internal/permissions"
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// newPermissionAgent returns an agent whose project rules are rules and whose prompt
+// answers with answer, counting how often it was asked
+func newPermissionAgent(t *testing.T, rules string, answer permissions.Choice, asked *int) *Agent {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	workDir := t.TempDir()
+	path := filepath.Join(workDir, permissions.RelPath())
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := permissions.Load(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Agent{
+		config: &Config{
+			Permissions: engine,
+			AskPermission: func(string, string) permissions.Choice {
+				*asked++
+				return answer
+			},
+		},
+		toolMgr: tools.NewToolManager(workDir, false),
+	}
+}
+
+func TestAuthorizeAppliesRulesToReads(t *testing.T) {
+	rules := `
+[[rule]]
+tool = "*"
+path = "secrets/**"
+action = "deny"
+
+[[rule]]
+tool = "get_file_content"
+path = "private/**"
+action = "ask"
+`
+	read := func(path string) genai.FunctionCall {
+		return genai.FunctionCall{Name: "get_file_content", Args: map[string]any{"file_path": path}}
+	}
+
+	var asked int
+	a := newPermissionAgent(t, rules, permissions.Refuse, &asked)
+	tests := []struct {
+		name     string
+		call     genai.FunctionCall
+		allowed  bool
+		asks     int
+		approval string
+	}{
+		{"default read", read("main.go"), true, 0, "auto"},
+		{"denied read", read("secrets/key.pem"), false, 0, "denied (rule)"},
+		{"denied git diff", genai.FunctionCall{Name: "git_diff", Args: map[string]any{"path": "secrets/key.pem"}}, false, 0, "denied (rule)"},
+		{"read that asks", read("private/notes.txt"), false, 1, "denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asked = 0
+			a.confirmation = ""
+			err := a.authorize(tt.call)
+			if (err == nil) != tt.allowed {
+				t.Errorf("authorize error = %v, want allowed=%v", err, tt.allowed)
+			}
+			if asked != tt.asks {
+				t.Errorf("asked %d times, want %d", asked, tt.asks)
+			}
+			if a.confirmation != tt.approval {
+				t.Errorf("approval = %q, want %q", a.confirmation, tt.approval)
+			}
+		})
+	}
+}
+
+func TestAuthorizeDryRunStillAsksForReads(t *testing.T) {
+	var asked int
+	a := newPermissionAgent(t, "[[rule]]\ntool = \"get_file_content\"\naction = \"ask\"\n", permissions.Once, &asked)
+	a.config.DryRun = true
+
+	if err := a.authorize(genai.FunctionCall{Name: "get_file_content", Args: map[string]any{"file_path": "a.txt"}}); err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if asked != 1 {
+		t.Errorf("asked %d times, want 1", asked)
+	}
+}
@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/google/generative-ai-go/genai"
+)
+
+const pinnedHeader = "The following files are pinned to the context by the user. Their content is re-read from disk for every request, so it is always current:\n\n"
+
+// withPins returns the messages to send for this request, with the current content of
+// pinned files placed ahead of the original prompt. The stored history is not modified,
+// so pinned content never gets truncated or summarized.
+func (a *Agent) withPins(messages []*genai.Content) []*genai.Content {
+	if len(a.config.PinnedFiles) == 0 || len(messages) == 0 {
+		return messages
+	}
+
+	files, err := a.toolMgr.ResolvePins(a.config.PinnedFiles)
+	if err != nil {
+		color.Yellow("Could not resolve pinned files: %v", err)
+		return messages
+	}
+
+	text, skipped := a.toolMgr.RenderPins(files)
+	if len(skipped) > 0 {
+		color.Yellow("Skipped pinned files over the size budget: %s", strings.Join(skipped, ", "))
+	}
+	if text == "" {
+		return messages
+	}
+
+	first := &genai.Content{
+		Role:  messages[0].Role,
+		Parts: append([]genai.Part{genai.Text(pinnedHeader + text)}, messages[0].Parts...),
+	}
+	return append([]*genai.Content{first}, messages[1:]...)
+}
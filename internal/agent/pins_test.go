@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestWithPinsReadsCurrentContent(t *testing.T) {
+	workDir := t.TempDir()
+	path := filepath.Join(workDir, "notes.md")
+	os.WriteFile(path, []byte("first version"), 0644)
+
+	a := &Agent{
+		config:  &Config{PinnedFiles: []string{"notes.md"}},
+		toolMgr: tools.NewToolManager(workDir, false),
+	}
+	history := []*genai.Content{
+		{Role: "user", Parts: []genai.Part{genai.Text("explain the notes")}},
+		{Role: "model", Parts: []genai.Part{genai.Text("ok")}},
+	}
+
+	sent := a.withPins(history)
+	if len(sent) != 2 || len(sent[0].Parts) != 2 {
+		t.Fatalf("pins not placed ahead of the first prompt: %+v", sent)
+	}
+	if text, _ := sent[0].Parts[0].(genai.Text); !strings.Contains(string(text), "first version") {
+		t.Errorf("pinned text = %q", text)
+	}
+	if len(history[0].Parts) != 1 {
+		t.Error("withPins modified the stored history")
+	}
+
+	os.WriteFile(path, []byte("second version"), 0644)
+	sent = a.withPins(history)
+	if text, _ := sent[0].Parts[0].(genai.Text); !strings.Contains(string(text), "second version") {
+		t.Errorf("pinned text not re-read from disk: %q", text)
+	}
+}
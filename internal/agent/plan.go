@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/fatih/color"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// PlanReviewer shows a proposed plan to the user. It returns the plan to execute,
+// possibly edited, and whether the user approved it.
+type PlanReviewer func(plan string) (string, bool, error)
+
+const planningInstruction = `[Planning mode] Do not change anything yet: only read-only tools are available. Investigate as much as you need, then reply with a numbered plan that lists every file you will create, change, move or delete (with its path) and every program you will run, each with a short reason. End your reply with the plan; it will be shown to the user for approval.`
+
+const executeInstruction = `The user approved the plan, which is now shown with the original request as "Approved plan" (the user may have edited it). Carry it out now. If you find you need to change a file or run a program that is not in the plan, say so and explain why before doing it.`
+
+// deviationNote is appended to the result of tool calls that were not in the approved plan
+const deviationNote = "[Note: this action was not in the approved plan. Tell the user why it was needed.]"
+
+// planFirst runs the planning phase: the model investigates with read-only tools and
+// proposes a plan, which the user reviews. On approval the plan is pinned to the
+// conversation and the returned messages ask the model to execute it.
+func (a *Agent) planFirst(ctx context.Context, messages []*genai.Content) ([]*genai.Content, bool, error) {
+	if a.config.ReviewPlan == nil {
+		return messages, false, fmt.Errorf("plan mode needs an interactive terminal to approve the plan")
+	}
+
+	color.Cyan("Planning (read-only)...")
+	a.readOnlyReason = "while planning; include the change in the plan instead"
+	a.setPinnedPrompt(messages, genai.Text(planningInstruction))
+
+	messages, plan, finished, err := a.loop(ctx, messages)
+	a.readOnlyReason = ""
+	if err != nil || !finished {
+		return messages, false, err
+	}
+	if strings.TrimSpace(plan) == "" {
+		color.Yellow("The model did not produce a plan.")
+		return messages, false, nil
+	}
+
+	plan, approved, err := a.config.ReviewPlan(strings.TrimSpace(plan))
+	if err != nil {
+		return messages, false, err
+	}
+	if !approved {
+		slog.Info("plan rejected", "session", a.session)
+		color.Yellow("Plan rejected; nothing was changed.")
+		return messages, false, nil
+	}
+	slog.Info("plan approved", "session", a.session, "plan", plan)
+
+	// Pin the approved plan so it survives summarization of older history. Reads made
+	// while planning may be repeated legitimately, so they don't count as loops.
+	a.plan = plan
+	a.seenCalls = make(map[string]bool)
+	a.setPinnedPrompt(messages, genai.Text("Approved plan:\n"+plan))
+	messages = append(messages, &genai.Content{
+		Role:  "user",
+		Parts: []genai.Part{genai.Text(executeInstruction)},
+	})
+	return messages, true, nil
+}
+
+// setPinnedPrompt replaces the text that accompanies the user's prompt in the first
+// message, which is kept through summarization (see history.go)
+func (a *Agent) setPinnedPrompt(messages []*genai.Content, extra genai.Part) {
+	old := len(a.pinnedPrompt)
+	a.pinnedPrompt = []genai.Part{a.pinnedPrompt[0], extra}
+
+	first := *messages[0]
+	first.Parts = append(append([]genai.Part{}, a.pinnedPrompt...), first.Parts[old:]...)
+	messages[0] = &first
+}
+
+// checkPlan reports whether a mutating tool call touches something the approved plan
+// does not mention, warning the user when it does
+func (a *Agent) checkPlan(fc genai.FunctionCall, paths []string) bool {
+	if a.plan == "" || !hashedTools[fc.Name] {
+		return false
+	}
+
+	planned := planPaths(a.plan)
+	for _, p := range paths {
+		if planned[p] {
+			continue
+		}
+		slog.Info("plan deviation", "tool", fc.Name, "path", p)
+		color.Yellow("Deviation from the approved plan: %s %s", fc.Name, p)
+		return true
+	}
+	return false
+}
+
+// planPaths returns the workdir-relative paths a plan mentions, slash-separated and cleaned.
+// Words are split on spaces and quoting or markdown punctuation, and sentence punctuation
+// is trimmed, so "Edit `cmd/main.go`." names cmd/main.go.
+func planPaths(plan string) map[string]bool {
+	words := strings.FieldsFunc(plan, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("`'\"()[]{}<>,;*", r)
+	})
+	paths := make(map[string]bool, len(words))
+	for _, w := range words {
+		w = strings.TrimRight(w, ".:!?")
+		if w == "" {
+			continue
+		}
+		paths[filepath.ToSlash(filepath.Clean(w))] = true
+	}
+	return paths
+}
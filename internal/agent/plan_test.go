@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestCheckPlanMatchesFullPaths(t *testing.T) {
+	a := &Agent{plan: `1. Edit ` + "`cmd/main.go`" + ` to parse the new flag.
+2. Add **internal/util/strings.go** with the helpers (data handling).
+3. Create ./docs/ for the guide, then write docs/guide.md.
+4. Run go test ./...`}
+
+	tests := []struct {
+		tool      string
+		path      string
+		deviation bool
+	}{
+		{"write_file", "cmd/main.go", false},
+		{"write_file", "internal/util/strings.go", false},
+		{"create_directory", "docs", false},
+		{"write_file", "docs/guide.md", false},
+		{"write_file", "main.go", true},          // Only the base name is in the plan
+		{"write_file", "strings.go", true},       // Likewise
+		{"write_file", "a", true},                // A substring of "data"
+		{"write_file", "internal/util", true},    // A prefix of a planned path
+		{"get_file_content", "secret.go", false}, // Reads are never deviations
+	}
+	for _, tt := range tests {
+		fc := genai.FunctionCall{Name: tt.tool}
+		if got := a.checkPlan(fc, []string{tt.path}); got != tt.deviation {
+			t.Errorf("checkPlan(%s %s) = %v, want %v", tt.tool, tt.path, got, tt.deviation)
+		}
+	}
+}
+
+func TestCheckPlanWithoutPlan(t *testing.T) {
+	a := &Agent{}
+	if a.checkPlan(genai.FunctionCall{Name: "write_file"}, []string{"anything.go"}) {
+		t.Error("reported a deviation with no approved plan")
+	}
+}
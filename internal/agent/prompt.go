@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/brandnova/nova-horizon-cli/internal/gemini"
+)
+
+const instructionsPreamble = "The user and project provided the following instructions. Follow them; project instructions take precedence over user instructions."
+
+// BuildSystemPrompt renders the effective system prompt for cfg: the configured template
+// (or the built-in one), followed by any configured extension and loaded instructions.
+func BuildSystemPrompt(cfg *Config) (string, error) {
+	tmpl := cfg.SystemPrompt
+	if tmpl == "" {
+		tmpl = gemini.DefaultSystemPrompt
+	}
+
+	data := gemini.PromptData{
+		WorkDir:  cfg.WorkDir,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		Model:    cfg.Model,
+		Date:     time.Now().Format("2006-01-02"),
+		AllowRun: cfg.AllowRun,
+		DryRun:   cfg.DryRun,
+		ReadOnly: cfg.ReadOnly,
+		Tools:    gemini.DescribeTools(gemini.BuildTools(cfg.ReadOnly)),
+
+		RunExtensions: cfg.ToolPolicy.RunExtensions,
+		ExecTimeout:   cfg.ToolPolicy.ExecTimeout.String(),
+	}
+
+	prompt, err := gemini.RenderSystemPrompt(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+
+	if cfg.SystemPromptAppend != "" {
+		extra, err := gemini.RenderSystemPrompt(cfg.SystemPromptAppend, data)
+		if err != nil {
+			return "", err
+		}
+		prompt += "\n\n" + extra
+	}
+
+	if cfg.Instructions != "" {
+		prompt += "\n\n" + instructionsPreamble + "\n\n" + cfg.Instructions
+	}
+
+	return prompt, nil
+}
@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildSystemPrompt(t *testing.T) {
+	cfg := &Config{
+		WorkDir:            "/src/app",
+		SystemPrompt:       "Agent for {{.WorkDir}}.",
+		SystemPromptAppend: "Dry run: {{.DryRun}}.",
+		Instructions:       "# Project instructions\n\nUse tabs.",
+	}
+	prompt, err := BuildSystemPrompt(cfg)
+	if err != nil {
+		t.Fatalf("BuildSystemPrompt: %v", err)
+	}
+	want := "Agent for /src/app.\n\nDry run: false.\n\n" + instructionsPreamble + "\n\n# Project instructions\n\nUse tabs."
+	if prompt != want {
+		t.Errorf("prompt = %q, want %q", prompt, want)
+	}
+
+	cfg.SystemPromptAppend = "{{.Missing}}"
+	if _, err := BuildSystemPrompt(cfg); err == nil {
+		t.Error("invalid append template was accepted")
+	}
+
+	cfg.SystemPrompt, cfg.SystemPromptAppend = "", ""
+	prompt, err = BuildSystemPrompt(cfg)
+	if err != nil || !strings.HasPrefix(prompt, "You are Nova Horizon") {
+		t.Errorf("default prompt = %.40q, %v", prompt, err)
+	}
+}
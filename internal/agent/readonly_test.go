@@ -0,0 +1,54 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/google/generative-ai-go/genai"
+)
+
+func TestReadOnlyRejectsMutatingTools(t *testing.T) {
+	tests := []struct {
+		name     string
+		readOnly bool
+		reason   string
+		want     string
+	}{
+		{"read-only mode", true, "in read-only mode; describe the change to the user instead", "read-only"},
+		{"planning", false, "while planning; include the change in the plan instead", "plan"},
+	}
+	for _, tt := range tests {
+		workDir := t.TempDir()
+		os.WriteFile(filepath.Join(workDir, "main.go"), []byte("package main\n"), 0644)
+		a := &Agent{
+			config:         &Config{ReadOnly: tt.readOnly},
+			toolMgr:        tools.NewToolManager(workDir, false),
+			readOnlyReason: tt.reason,
+		}
+
+		for _, fc := range []genai.FunctionCall{
+			{Name: "write_file", Args: map[string]any{"file_path": "main.go", "content": "changed"}},
+			{Name: "delete_file", Args: map[string]any{"file_path": "main.go"}},
+			{Name: "run_file", Args: map[string]any{"file_path": "main.go"}},
+		} {
+			a.confirmation = ""
+			if _, err := a.executeFunction(context.Background(), fc); err == nil {
+				t.Errorf("%s: %s was allowed", tt.name, fc.Name)
+			}
+			if a.confirmation != tt.want {
+				t.Errorf("%s: %s recorded as %q, want %q", tt.name, fc.Name, a.confirmation, tt.want)
+			}
+		}
+		if data, _ := os.ReadFile(filepath.Join(workDir, "main.go")); string(data) != "package main\n" {
+			t.Errorf("%s: main.go changed to %q", tt.name, data)
+		}
+
+		out, err := a.executeFunction(context.Background(), genai.FunctionCall{Name: "get_file_content", Args: map[string]any{"file_path": "main.go"}})
+		if err != nil || out == "" {
+			t.Errorf("%s: get_file_content = %q, %v; want the file", tt.name, out, err)
+		}
+	}
+}
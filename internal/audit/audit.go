@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brandnova/nova-horizon-cli/internal/config"
+)
+
+// FileName is the audit log kept in the project's .nova directory
+const FileName = "audit.jsonl"
+
+// Entry is one tool call as recorded in the audit log. Hashes map each affected
+// path to "sha256:<hex>" of its content; an empty hash means the file did not exist.
+type Entry struct {
+	Time     time.Time         `json:"time"`
+	Session  string            `json:"session"`
+	Step     int               `json:"step"`
+	Tool     string            `json:"tool"`
+	Args     map[string]any    `json:"args,omitempty"`
+	Paths    []string          `json:"paths,omitempty"`
+	Before   map[string]string `json:"hash_before,omitempty"`
+	After    map[string]string `json:"hash_after,omitempty"`
+	ExitCode *int              `json:"exit_code,omitempty"`
+	Approval string            `json:"approval,omitempty"`
+	DryRun   bool              `json:"dry_run,omitempty"`
+	Error    string            `json:"error,omitempty"`
+
+	// PlanDeviation marks a change that was not in the plan approved in --plan mode
+	PlanDeviation bool `json:"plan_deviation,omitempty"`
+}
+
+// Log appends entries for one session to a workdir's audit log
+type Log struct {
+	mu      sync.Mutex
+	path    string
+	session string
+}
+
+// RelPath returns the audit log location relative to the working directory
+func RelPath() string {
+	return filepath.Join(config.ProjectConfigDir, FileName)
+}
+
+// Path returns the audit log location for workDir
+func Path(workDir string) string {
+	return filepath.Join(workDir, RelPath())
+}
+
+// Open returns a Log writing to workDir's audit log under the given session id.
+// The file is created on the first Record.
+func Open(workDir string, session string) *Log {
+	return &Log{path: Path(workDir), session: session}
+}
+
+// NewSessionID returns a sortable, unique id for an agent run
+func NewSessionID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// Record appends e to the log, filling in the time and session id
+func (l *Log) Record(e Entry) error {
+	e.Session = l.session
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+// Filter selects audit entries; zero fields match everything
+type Filter struct {
+	Session string
+	Path    string // Exact path, directory prefix or glob
+	Since   time.Time
+	Until   time.Time
+}
+
+// Match reports whether e passes the filter
+func (f Filter) Match(e Entry) bool {
+	if f.Session != "" && !strings.HasPrefix(e.Session, f.Session) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && e.Time.After(f.Until) {
+		return false
+	}
+	if f.Path == "" {
+		return true
+	}
+
+	want := filepath.ToSlash(filepath.Clean(f.Path))
+	for _, p := range e.Paths {
+		if p == want || strings.HasPrefix(p, strings.TrimSuffix(want, "/")+"/") {
+			return true
+		}
+		if ok, _ := filepath.Match(want, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the entries of the audit log at path that match f, oldest first.
+// A missing log yields no entries.
+func Read(path string, f Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid audit entry: %w", path, n, err)
+		}
+		if f.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	workDir := t.TempDir()
+	first := Open(workDir, "20240501-140312-aaaaaa")
+	second := Open(workDir, "20240502-090000-bbbbbb")
+
+	code := 1
+	entries := []struct {
+		log *Log
+		e   Entry
+	}{
+		{first, Entry{Step: 1, Tool: "write_file", Paths: []string{"src/main.go"}, After: map[string]string{"src/main.go": "sha256:ab"}, Approval: "user"}},
+		{first, Entry{Step: 2, Tool: "run_file", Paths: []string{"test.sh"}, ExitCode: &code, Approval: "rule"}},
+		{second, Entry{Step: 1, Tool: "delete_file", Paths: []string{"docs/old.md"}, Approval: "denied", Error: "the user denied permission"}},
+	}
+	for _, entry := range entries {
+		if err := entry.log.Record(entry.e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	all, err := Read(Path(workDir), Filter{})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("read %d entries, want 3", len(all))
+	}
+	if all[0].Session != "20240501-140312-aaaaaa" || all[0].Time.IsZero() {
+		t.Errorf("entry not stamped with session and time: %+v", all[0])
+	}
+	if all[1].ExitCode == nil || *all[1].ExitCode != 1 {
+		t.Errorf("exit code = %v, want 1", all[1].ExitCode)
+	}
+	if all[0].After["src/main.go"] != "sha256:ab" {
+		t.Errorf("hashes not kept: %+v", all[0].After)
+	}
+
+	// The log is JSON lines, one per call
+	data, _ := os.ReadFile(filepath.Join(workDir, ".nova", FileName))
+	if n := strings.Count(string(data), "\n"); n != 3 {
+		t.Errorf("log has %d lines, want 3", n)
+	}
+}
+
+func TestReadMissingLog(t *testing.T) {
+	entries, err := Read(filepath.Join(t.TempDir(), "audit.jsonl"), Filter{})
+	if err != nil || entries != nil {
+		t.Errorf("Read = %v, %v; want no entries and no error", entries, err)
+	}
+}
+
+func TestReadReportsCorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	os.WriteFile(path, []byte("{\"tool\":\"write_file\"}\nnot json\n"), 0644)
+	if _, err := Read(path, Filter{}); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("Read error = %v, want one naming line 2", err)
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	at := time.Date(2024, 5, 1, 14, 3, 12, 0, time.UTC)
+	e := Entry{Time: at, Session: "20240501-140312-aaaaaa", Paths: []string{"src/app/main.go"}}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"session prefix", Filter{Session: "20240501"}, true},
+		{"other session", Filter{Session: "20240502"}, false},
+		{"exact path", Filter{Path: "src/app/main.go"}, true},
+		{"directory", Filter{Path: "src/"}, true},
+		{"directory without slash", Filter{Path: "src"}, true},
+		{"name prefix is not a directory", Filter{Path: "sr"}, false},
+		{"glob", Filter{Path: "src/app/*.go"}, true},
+		{"other path", Filter{Path: "docs"}, false},
+		{"since before", Filter{Since: at.Add(-time.Hour)}, true},
+		{"since after", Filter{Since: at.Add(time.Hour)}, false},
+		{"until before", Filter{Until: at.Add(-time.Hour)}, false},
+		{"within range", Filter{Since: at.Add(-time.Hour), Until: at.Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(e); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
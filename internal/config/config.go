@@ -2,46 +2,292 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/brandnova/nova-horizon-cli/internal/defaults"
 	"github.com/pelletier/go-toml"
 )
 
+const (
+	// ProjectConfigDir is the per-project directory holding config.toml and other project state
+	ProjectConfigDir = ".nova"
+	configFileName   = "config.toml"
+)
+
 type Config struct {
-	APIKey string `toml:"api_key"`
+	APIKey             string
+	APIKeyEnv          string
+	APIKeyCmd          string
+	APIKeyKeyring      bool
+	Provider           string
+	Profile            string
+	Model              string
+	WorkDir            string
+	Verbose            bool
+	DryRun             bool
+	AllowRun           bool
+	ApplyDiff          bool
+	Plan               bool
+	ReadOnly           bool
+	MaxSteps           int
+	MaxRetries         int
+	ContextLimit       int
+	SystemPrompt       string
+	SystemPromptAppend string
+	Tools              ToolsConfig
+	Log                LogConfig
+	Git                GitConfig
+
+	// Sources records where each key was last set: a file path, environment variable or flag
+	Sources map[string]string
+
+	// Warnings are non-fatal problems found while loading, such as insecure file permissions
+	Warnings []string
+}
+
+// ToolsConfig holds the [tools] policy settings
+type ToolsConfig struct {
+	ExecTimeout   time.Duration
+	MaxFileSize   int
+	RunExtensions []string
+
+	WriteExtensions []string
+	ProtectedPaths  []string
+	MaxFilesWritten int
+	CreateDirs      bool
 }
 
-func LoadConfig() (*Config, error) {
-	// First, try environment variable
-	apiKey := os.Getenv("GEMINI_API_KEY")
-	if apiKey != "" {
-		return &Config{APIKey: apiKey}, nil
+// LogConfig holds the [log] settings
+type LogConfig struct {
+	Level  string
+	Format string
+	File   string
+}
+
+// GitConfig holds the [git] settings used when the working directory is in a git repository
+type GitConfig struct {
+	Enabled    bool
+	AllowDirty bool
+	AutoCommit bool
+	Branch     string
+}
+
+// Defaults returns the built-in configuration
+func Defaults() *Config {
+	return &Config{
+		Provider:     "gemini",
+		Model:        "gemini-2.5-flash",
+		MaxSteps:     10,
+		MaxRetries:   3,
+		ContextLimit: 200000,
+		Tools: ToolsConfig{
+			ExecTimeout:   defaults.ExecTimeout,
+			MaxFileSize:   defaults.MaxFileSize,
+			RunExtensions: append([]string(nil), defaults.RunExtensions...),
+
+			WriteExtensions: append([]string(nil), defaults.WriteExtensions...),
+			ProtectedPaths:  append([]string(nil), defaults.ProtectedPaths...),
+			MaxFilesWritten: defaults.MaxFilesWritten,
+			CreateDirs:      true,
+		},
+		Log: LogConfig{
+			Level:  "warn",
+			Format: "text",
+		},
+		Git: GitConfig{
+			Enabled:    true,
+			AutoCommit: true,
+		},
+		Sources: make(map[string]string),
 	}
+}
 
-	// Then try config file
-	homeDir, err := os.UserHomeDir()
+// Load builds the configuration by layering, from lowest to highest precedence:
+// built-in defaults, the user config file, the nearest project .nova/config.toml
+// (searched upward from startDir), the selected profile and environment variables.
+// The profile is the given name, else $NOVA_PROFILE, else the files' "profile" key.
+// Command-line flags are applied on top by the caller. Unlike LoadConfig it does not
+// require an API key.
+func Load(startDir string, profile string) (*Config, error) {
+	cfg := Defaults()
+
+	var profiles []profileSet
+	for _, path := range ConfigFiles(startDir) {
+		set, err := loadFile(cfg, path, path != UserConfigPath())
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, set)
+	}
+
+	if profile == "" {
+		if profile = os.Getenv("NOVA_PROFILE"); profile != "" {
+			cfg.Sources["profile"] = "env NOVA_PROFILE"
+		}
+	}
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	if profile != "" {
+		if err := applyProfile(cfg, profiles, profile); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Profile = profile
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	// A profile (or file) may point at a different variable holding the key
+	if cfg.APIKeyEnv != "" {
+		if key := os.Getenv(cfg.APIKeyEnv); key != "" {
+			slog.Debug("using API key from api_key_env", "variable", cfg.APIKeyEnv)
+			cfg.APIKey = key
+			cfg.Sources["api_key"] = "env " + cfg.APIKeyEnv
+		}
+	}
+
+	return cfg, nil
+}
+
+// LoadConfig loads the configuration and ensures an API key is available. Keys from the
+// environment win; otherwise api_key_cmd and then the OS keyring are tried before a
+// plaintext api_key from a config file.
+func LoadConfig(startDir string, profile string) (*Config, error) {
+	cfg, err := Load(startDir, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveAPIKey(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.APIKey == "" {
+		if cfg.APIKeyEnv != "" {
+			return nil, fmt.Errorf("no API key found: %s (from api_key_env) and GEMINI_API_KEY are not set and no 'api_key' found in config file at %s", cfg.APIKeyEnv, UserConfigPath())
+		}
+		return nil, fmt.Errorf("GEMINI_API_KEY environment variable not set and no 'api_key' found in config file at %s", UserConfigPath())
+	}
+
+	return cfg, nil
+}
+
+// ConfigFiles returns the existing config files that apply to startDir, lowest precedence first.
+// A file set with SetConfigFile is always included, so a missing one is reported when loading.
+func ConfigFiles(startDir string) []string {
+	var files []string
+	if path := UserConfigPath(); explicitConfigFile != "" || fileExists(path) {
+		files = append(files, path)
+	}
+	if path := ProjectConfigPath(startDir); path != "" {
+		files = append(files, path)
+	}
+	return files
+}
+
+// ProjectConfigPath returns the nearest .nova/config.toml at or above startDir, or ""
+func ProjectConfigPath(startDir string) string {
+	if startDir == "" {
+		return ""
+	}
+	dir := startDir
+	for {
+		path := filepath.Join(dir, ProjectConfigDir, configFileName)
+		if fileExists(path) {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func loadFile(cfg *Config, path string, project bool) (profileSet, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("GEMINI_API_KEY not set and could not read home directory")
+		return profileSet{}, fmt.Errorf("failed to read config file at %s: %w", path, err)
 	}
 
-	configPath := filepath.Join(homeDir, ".config", "nova-horizon", "config.toml")
-	data, err := os.ReadFile(configPath)
+	tree, err := toml.LoadBytes(data)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("GEMINI_API_KEY environment variable not set and no config file found at %s", configPath)
+		return profileSet{}, fmt.Errorf("failed to parse config file at %s: %w", path, err)
+	}
+
+	if project {
+		dropUserOnlyKeys(cfg, tree, path)
+	}
+
+	set, err := splitProfiles(tree, path)
+	if err != nil {
+		return profileSet{}, err
+	}
+
+	if tree.Has("api_key") {
+		if warning := checkSecretPermissions(path); warning != "" {
+			cfg.Warnings = append(cfg.Warnings, warning)
 		}
-		return nil, fmt.Errorf("failed to read config file at %s: %w", configPath, err)
 	}
 
-	var cfg Config
-	if err := toml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("failed to parse config file at %s: %w", configPath, err)
+	slog.Debug("loading config file", "path", path)
+
+	return set, applyTree(cfg, tree, path, path)
+}
+
+// dropUserOnlyKeys removes keys that a cloned repository must not be able to set,
+// with a warning for each
+func dropUserOnlyKeys(cfg *Config, tree *toml.Tree, path string) {
+	for _, name := range flattenKeys(tree, "") {
+		_, keyName := splitKeyName(name)
+		if key, ok := LookupKey(keyName); ok && key.UserOnly {
+			cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s:%d: ignoring %q: it is only allowed in the user config (%s)", path, tree.GetPosition(name).Line, name, UserConfigPath()))
+			tree.Delete(name)
+		}
 	}
+}
 
-	if cfg.APIKey == "" {
-		return nil, fmt.Errorf("config file at %s parsed successfully but 'api_key' is missing or empty", configPath)
+func applyEnv(cfg *Config) error {
+	for _, key := range Keys {
+		for _, env := range key.Env {
+			raw, ok := os.LookupEnv(env)
+			if !ok || raw == "" {
+				continue
+			}
+
+			v, err := key.ParseString(raw)
+			if err == nil {
+				err = key.Set(cfg, v)
+			}
+			if err != nil {
+				return fmt.Errorf("environment variable %s (key %q): %w", env, key.Name, err)
+			}
+			cfg.Sources[key.Name] = "env " + env
+			break
+		}
+	}
+	return nil
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
 	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
 
-	return &cfg, nil
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
 }
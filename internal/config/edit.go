@@ -0,0 +1,206 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// bareKey matches TOML keys that need no quoting
+var bareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// configEdit changes single keys in a config file's text, touching only the lines
+// that hold them so comments, ordering and layout are kept
+type configEdit struct {
+	lines []string // Including line endings
+	eol   string
+	old   *toml.Tree // Parsed from lines, for key positions
+}
+
+func newConfigEdit(data string) (*configEdit, error) {
+	old, err := toml.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	e := &configEdit{lines: strings.SplitAfter(data, "\n"), eol: "\n", old: old}
+	if strings.Contains(data, "\r\n") {
+		e.eol = "\r\n"
+	}
+	if n := len(e.lines); n > 0 && e.lines[n-1] == "" {
+		e.lines = e.lines[:n-1]
+	}
+	return e, nil
+}
+
+func (e *configEdit) String() string {
+	return strings.Join(e.lines, "")
+}
+
+// set replaces the key's value in place, or inserts it into its table
+func (e *configEdit) set(path []string, encoded string) bool {
+	if e.old.HasPath(path) {
+		start, end, ok := e.valueLines(path)
+		if !ok {
+			return false
+		}
+		line := e.lines[start]
+		eq := strings.IndexByte(line, '=')
+		if eq < 0 {
+			return false
+		}
+		replaced := strings.TrimRight(line[:eq], " \t") + " = " + encoded
+		if end == start+1 {
+			if comment := trailingComment(line); comment != "" {
+				replaced += " " + comment
+			}
+		}
+		e.replace(start, end, replaced+e.eol)
+		return true
+	}
+
+	table, leaf := path[:len(path)-1], quoteKey(path[len(path)-1])
+	line := leaf + " = " + encoded + e.eol
+	if len(table) == 0 {
+		e.replace(e.topLevelEnd(), e.topLevelEnd(), line)
+		return true
+	}
+
+	// After the table's header, if the file has one
+	if sub, ok := e.old.GetPath(table).(*toml.Tree); ok && sub != nil {
+		header := e.old.GetPositionPath(table).Line - 1
+		if header >= 0 && header < len(e.lines) && strings.HasPrefix(strings.TrimSpace(e.lines[header]), "[") {
+			e.replace(header+1, header+1, line)
+			return true
+		}
+		return false
+	}
+
+	if n := len(e.lines); n > 0 {
+		if !strings.HasSuffix(e.lines[n-1], "\n") {
+			e.lines[n-1] += e.eol
+		}
+		e.lines = append(e.lines, e.eol)
+	}
+	quoted := make([]string, len(table))
+	for i, part := range table {
+		quoted[i] = quoteKey(part)
+	}
+	e.lines = append(e.lines, "["+strings.Join(quoted, ".")+"]"+e.eol, line)
+	return true
+}
+
+// unset removes the lines holding the key's value
+func (e *configEdit) unset(path []string) bool {
+	start, end, ok := e.valueLines(path)
+	if !ok {
+		return false
+	}
+	e.replace(start, end, "")
+	return true
+}
+
+// valueLines returns the range of lines holding a key and its value, which may span
+// several lines (e.g. an array written one item per line)
+func (e *configEdit) valueLines(path []string) (start int, end int, ok bool) {
+	start = e.old.GetPositionPath(path).Line - 1
+	if start < 0 || start >= len(e.lines) {
+		return 0, 0, false
+	}
+	for end = start + 1; end <= len(e.lines); end++ {
+		if _, err := toml.Load(strings.Join(e.lines[start:end], "")); err == nil {
+			return start, end, true
+		}
+	}
+	return 0, 0, false
+}
+
+// topLevelEnd returns where a new top-level key goes: before the first table header
+// and the comments and blank lines above it, or at the end of the file
+func (e *configEdit) topLevelEnd() int {
+	for i, line := range e.lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			for i > 0 && strings.HasPrefix(strings.TrimSpace(e.lines[i-1]), "#") {
+				i--
+			}
+			for i > 0 && strings.TrimSpace(e.lines[i-1]) == "" {
+				i--
+			}
+			return i
+		}
+	}
+	if n := len(e.lines); n > 0 && !strings.HasSuffix(e.lines[n-1], "\n") {
+		e.lines[n-1] += e.eol
+	}
+	return len(e.lines)
+}
+
+func (e *configEdit) replace(start int, end int, text string) {
+	lines := append([]string{}, e.lines[:start]...)
+	if text != "" {
+		lines = append(lines, text)
+	}
+	e.lines = append(lines, e.lines[end:]...)
+}
+
+// trailingComment returns the comment after a one-line key/value pair, if any. A '#'
+// starts the comment when the text before it is a complete pair, so a '#' inside a
+// string is skipped.
+func trailingComment(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	for i := strings.IndexByte(line, '#'); i >= 0; {
+		if _, err := toml.Load(line[:i]); err == nil {
+			return line[i:]
+		}
+		next := strings.IndexByte(line[i+1:], '#')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return ""
+}
+
+// keyPath splits a dotted key name into its TOML path; profile names are kept whole
+func keyPath(name string) []string {
+	if profile, key := splitKeyName(name); profile != "" {
+		return append([]string{"profiles", profile}, strings.Split(key, ".")...)
+	}
+	return strings.Split(name, ".")
+}
+
+func quoteKey(part string) string {
+	if bareKey.MatchString(part) {
+		return part
+	}
+	return strconv.Quote(part)
+}
+
+// encodeValue renders a value as it appears after "key = " in a TOML file
+func encodeValue(v interface{}) (string, error) {
+	tree, err := toml.TreeFromMap(map[string]interface{}{"v": v})
+	if err != nil {
+		return "", err
+	}
+	out, err := tree.ToTomlString()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(out, "v = "), "\n"), nil
+}
+
+// writeEdit writes an edited config file, checking that the edit decodes to want. If it
+// does not (e.g. the key sits in an inline table), the file is rewritten from want,
+// which drops its comments and formatting.
+func writeEdit(path string, edit *configEdit, ok bool, want *toml.Tree) error {
+	if ok {
+		if got, err := toml.Load(edit.String()); err == nil && reflect.DeepEqual(got.ToMap(), want.ToMap()) {
+			return writeFile(path, edit.String())
+		}
+	}
+	slog.Warn("could not edit the config file in place; rewriting it without its comments", "path", path)
+	return writeTree(path, want)
+}
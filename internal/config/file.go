@@ -0,0 +1,275 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// ProjectConfigFile returns where the project config for workDir lives: the nearest
+// existing .nova/config.toml, or a new one directly in workDir.
+func ProjectConfigFile(workDir string) string {
+	if path := ProjectConfigPath(workDir); path != "" {
+		return path
+	}
+	return filepath.Join(workDir, ProjectConfigDir, configFileName)
+}
+
+// splitKeyName separates an optional "profiles.<name>." prefix from a key name
+func splitKeyName(name string) (profile string, key string) {
+	if rest, ok := strings.CutPrefix(name, "profiles."); ok {
+		if i := strings.Index(rest, "."); i > 0 {
+			return rest[:i], rest[i+1:]
+		}
+	}
+	return "", name
+}
+
+// lookupSettable resolves a key name, which may be scoped to a profile, to its schema entry
+func lookupSettable(name string) (*Key, error) {
+	profile, keyName := splitKeyName(name)
+	key, ok := LookupKey(keyName)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", name)
+	}
+	if profile != "" && key.Name == "profile" {
+		return nil, fmt.Errorf("profile %q cannot select another profile", profile)
+	}
+	return key, nil
+}
+
+// checkUserOnly rejects writing a user-only key to any file but the user config
+func checkUserOnly(key *Key, name string, path string) error {
+	if key.UserOnly && path != UserConfigPath() {
+		return fmt.Errorf("key %q is only allowed in the user config", name)
+	}
+	return nil
+}
+
+// GetFileValue returns the raw value of a key stored in a single config file
+func GetFileValue(path string, name string) (string, bool, error) {
+	key, err := lookupSettable(name)
+	if err != nil {
+		return "", false, err
+	}
+
+	tree, err := readTree(path)
+	if err != nil {
+		return "", false, err
+	}
+	if !tree.Has(name) {
+		return "", false, nil
+	}
+
+	v, err := key.fromTOML(tree.Get(name))
+	if err != nil {
+		return "", false, fmt.Errorf("%s: key %q: %w", path, name, err)
+	}
+	return key.Format(v), true, nil
+}
+
+// SetFileValue parses value for the named key and stores it in the config file at path,
+// creating the file (mode 0600) if needed.
+func SetFileValue(path string, name string, value string) error {
+	key, err := lookupSettable(name)
+	if err != nil {
+		return err
+	}
+	if err := checkUserOnly(key, name, path); err != nil {
+		return err
+	}
+
+	v, err := key.ParseString(value)
+	if err == nil {
+		err = key.Set(Defaults(), v)
+	}
+	if err != nil {
+		return fmt.Errorf("key %q: %w", name, err)
+	}
+
+	edit, want, err := readEdit(path)
+	if err != nil {
+		return err
+	}
+	keys := keyPath(name)
+	want.SetPath(keys, key.toTOML(v))
+
+	encoded, err := encodeValue(key.toTOML(v))
+	if err != nil {
+		return fmt.Errorf("failed to encode %q: %w", name, err)
+	}
+	return writeEdit(path, edit, edit.set(keys, encoded), want)
+}
+
+// UnsetFileValue removes a key from the config file at path
+func UnsetFileValue(path string, name string) error {
+	if _, err := lookupSettable(name); err != nil {
+		return err
+	}
+
+	edit, want, err := readEdit(path)
+	if err != nil {
+		return err
+	}
+	keys := keyPath(name)
+	if !want.HasPath(keys) {
+		return nil
+	}
+	if err := want.DeletePath(keys); err != nil {
+		return fmt.Errorf("failed to remove %q: %w", name, err)
+	}
+	return writeEdit(path, edit, edit.unset(keys), want)
+}
+
+// WriteValues writes a new config file containing the given keys (values as strings)
+func WriteValues(path string, values map[string]string) error {
+	tree, err := toml.TreeFromMap(map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+
+	for name, value := range values {
+		key, err := lookupSettable(name)
+		if err != nil {
+			return err
+		}
+		if err := checkUserOnly(key, name, path); err != nil {
+			return err
+		}
+		v, err := key.ParseString(value)
+		if err == nil {
+			err = key.Set(Defaults(), v)
+		}
+		if err != nil {
+			return fmt.Errorf("key %q: %w", name, err)
+		}
+		tree.Set(name, key.toTOML(v))
+	}
+
+	return writeTree(path, tree)
+}
+
+// ValidateFile checks every key in a config file and returns all problems found
+func ValidateFile(path string, project bool) []error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read config file at %s: %w", path, err)}
+	}
+
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return []error{fmt.Errorf("failed to parse config file at %s: %w", path, err)}
+	}
+
+	var errs []error
+	for _, name := range flattenKeys(tree, "") {
+		line := tree.GetPosition(name).Line
+		key, err := lookupSettable(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", path, line, err))
+			continue
+		}
+
+		if project && key.UserOnly {
+			errs = append(errs, fmt.Errorf("%s:%d: key %q is only allowed in the user config", path, line, name))
+			continue
+		}
+
+		v, err := key.fromTOML(tree.Get(name))
+		if err == nil {
+			err = key.Set(Defaults(), v)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: key %q: %w", path, line, name, err))
+		}
+	}
+	return errs
+}
+
+// MaskSecret hides all but the last four characters of a secret value
+func MaskSecret(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "********"
+	}
+	return "********" + s[len(s)-4:]
+}
+
+func readTree(path string) (*toml.Tree, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return toml.TreeFromMap(map[string]interface{}{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file at %s: %w", path, err)
+	}
+
+	tree, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file at %s: %w", path, err)
+	}
+	return tree, nil
+}
+
+// readEdit reads the config file at path for a single-key edit, returning the editor
+// and a tree of the current values for the caller to change alike
+func readEdit(path string) (*configEdit, *toml.Tree, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("failed to read config file at %s: %w", path, err)
+	}
+
+	edit, err := newConfigEdit(string(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file at %s: %w", path, err)
+	}
+	want, err := toml.LoadBytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file at %s: %w", path, err)
+	}
+	return edit, want, nil
+}
+
+// writeTree replaces the file at path with tree, readable only by the owner
+func writeTree(path string, tree *toml.Tree) error {
+	out, err := tree.ToTomlString()
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return writeFile(path, out)
+}
+
+// writeFile atomically replaces the file at path with content, readable only by the owner
+func writeFile(path string, out string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.toml")
+	if err != nil {
+		return fmt.Errorf("failed to write config file at %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(out); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file at %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file at %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file at %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write config file at %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const commentedConfig = `# Nova Horizon settings
+model = "gemini-2.5-flash" # fast enough for most work
+
+# Logging
+[log]
+level = "info"
+
+[tools]
+# Scripts the agent may run
+run_extensions = [
+  ".py",
+  ".sh",
+]
+max_file_size = 1048576
+
+[profiles.review]
+dry_run = true
+`
+
+func TestSetFileValueKeepsComments(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{
+			name: "replace with trailing comment", key: "model", value: "gemini-2.5-pro",
+			want: `# Nova Horizon settings
+model = "gemini-2.5-pro" # fast enough for most work
+
+# Logging
+[log]
+level = "info"
+
+[tools]
+# Scripts the agent may run
+run_extensions = [
+  ".py",
+  ".sh",
+]
+max_file_size = 1048576
+
+[profiles.review]
+dry_run = true
+`,
+		},
+		{
+			name: "replace multi-line array", key: "tools.run_extensions", value: ".go",
+			want: `# Nova Horizon settings
+model = "gemini-2.5-flash" # fast enough for most work
+
+# Logging
+[log]
+level = "info"
+
+[tools]
+# Scripts the agent may run
+run_extensions = [".go"]
+max_file_size = 1048576
+
+[profiles.review]
+dry_run = true
+`,
+		},
+		{
+			name: "new top-level key", key: "max_steps", value: "20",
+			want: `# Nova Horizon settings
+model = "gemini-2.5-flash" # fast enough for most work
+max_steps = 20
+
+# Logging
+[log]
+level = "info"
+
+[tools]
+# Scripts the agent may run
+run_extensions = [
+  ".py",
+  ".sh",
+]
+max_file_size = 1048576
+
+[profiles.review]
+dry_run = true
+`,
+		},
+		{
+			name: "new key in existing table", key: "profiles.review.model", value: "gemini-2.5-pro",
+			want: `# Nova Horizon settings
+model = "gemini-2.5-flash" # fast enough for most work
+
+# Logging
+[log]
+level = "info"
+
+[tools]
+# Scripts the agent may run
+run_extensions = [
+  ".py",
+  ".sh",
+]
+max_file_size = 1048576
+
+[profiles.review]
+model = "gemini-2.5-pro"
+dry_run = true
+`,
+		},
+		{
+			name: "new table", key: "git.branch", value: "nova",
+			want: commentedConfig + "\n[git]\nbranch = \"nova\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The user config, which may hold every key
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+			path := UserConfigPath()
+			writeConfig(t, path, commentedConfig)
+
+			if err := SetFileValue(path, tt.key, tt.value); err != nil {
+				t.Fatalf("SetFileValue: %v", err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != tt.want {
+				t.Errorf("file =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnsetFileValueKeepsComments(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := UserConfigPath()
+	writeConfig(t, path, commentedConfig)
+
+	if err := UnsetFileValue(path, "tools.run_extensions"); err != nil {
+		t.Fatalf("UnsetFileValue: %v", err)
+	}
+	want := `# Nova Horizon settings
+model = "gemini-2.5-flash" # fast enough for most work
+
+# Logging
+[log]
+level = "info"
+
+[tools]
+# Scripts the agent may run
+max_file_size = 1048576
+
+[profiles.review]
+dry_run = true
+`
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSetFileValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nova", "config.toml")
+	if err := SetFileValue(path, "tools.exec_timeout", "45s"); err != nil {
+		t.Fatalf("SetFileValue: %v", err)
+	}
+	if got, _, err := GetFileValue(path, "tools.exec_timeout"); err != nil || got != "45s" {
+		t.Errorf("tools.exec_timeout = %q (%v), want 45s", got, err)
+	}
+}
+
+func TestSetFileValueInlineTableFallsBack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, "# settings\ntools = { exec_timeout = \"30s\" }\n")
+
+	if err := SetFileValue(path, "tools.exec_timeout", "1m"); err != nil {
+		t.Fatalf("SetFileValue: %v", err)
+	}
+	if got, _, err := GetFileValue(path, "tools.exec_timeout"); err != nil || got != "1m0s" {
+		t.Errorf("tools.exec_timeout = %q (%v), want 1m0s", got, err)
+	}
+}
+
+func TestWriteValuesRejectsUserOnlyKeysInProject(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	project := filepath.Join(t.TempDir(), ProjectConfigDir, configFileName)
+
+	if err := WriteValues(project, map[string]string{"model": "gemini-2.5-pro", "allow_run": "true"}); err == nil {
+		t.Error("WriteValues wrote allow_run to a project config")
+	}
+	if _, err := os.Stat(project); !os.IsNotExist(err) {
+		t.Errorf("project config was created (%v)", err)
+	}
+
+	if err := WriteValues(project, map[string]string{"model": "gemini-2.5-pro"}); err != nil {
+		t.Fatalf("WriteValues: %v", err)
+	}
+	if errs := ValidateFile(project, true); len(errs) != 0 {
+		t.Errorf("ValidateFile: %v", errs)
+	}
+	if err := WriteValues(UserConfigPath(), map[string]string{"allow_run": "true"}); err != nil {
+		t.Errorf("WriteValues to the user config: %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// MaxInstructionsSize caps each instructions file so it can't crowd out the conversation
+	MaxInstructionsSize = 50000
+)
+
+// projectInstructionFiles are looked up, in order, in the working directory and each parent
+var projectInstructionFiles = []string{"NOVA.md", filepath.Join(".nova", "instructions.md")}
+
+// InstructionSource is one instructions file that was found and loaded
+type InstructionSource struct {
+	Scope   string // "user" or "project"
+	Path    string
+	Content string
+}
+
+// LoadInstructions finds the user-level instructions file and the nearest project
+// instructions file (walking up from workDir). Missing files are not an error.
+func LoadInstructions(workDir string) ([]InstructionSource, error) {
+	var sources []InstructionSource
+
+	if dir, err := configDir(); err == nil {
+		for _, name := range []string{"NOVA.md", "instructions.md"} {
+			src, err := readInstructions("user", filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			if src != nil {
+				sources = append(sources, *src)
+				break
+			}
+		}
+	}
+
+	dir := workDir
+	for {
+		found := false
+		for _, name := range projectInstructionFiles {
+			src, err := readInstructions("project", filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			if src != nil {
+				sources = append(sources, *src)
+				found = true
+				break
+			}
+		}
+		if found {
+			break
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+
+	return sources, nil
+}
+
+// FormatInstructions merges loaded instruction files into one block for the system prompt.
+// Project instructions come last so they take precedence over user ones.
+func FormatInstructions(sources []InstructionSource) string {
+	var b strings.Builder
+	for _, src := range sources {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "# %s instructions (from %s)\n\n%s", strings.ToUpper(src.Scope[:1])+src.Scope[1:], src.Path, strings.TrimSpace(src.Content))
+	}
+	return b.String()
+}
+
+func readInstructions(scope, path string) (*InstructionSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read instructions file at %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+	if info.Size() > MaxInstructionsSize {
+		return nil, fmt.Errorf("instructions file at %s is too large (%d bytes, max %d)", path, info.Size(), MaxInstructionsSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read instructions file at %s: %w", path, err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, nil
+	}
+
+	slog.Info("loaded instructions", "scope", scope, "path", path, "bytes", len(data))
+	return &InstructionSource{Scope: scope, Path: path, Content: string(data)}, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadInstructions(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	userDir := filepath.Join(configHome, appName)
+	os.MkdirAll(userDir, 0755)
+	os.WriteFile(filepath.Join(userDir, "NOVA.md"), []byte("Prefer tabs.\n"), 0644)
+
+	// The nearest project file wins; the one further up is not loaded
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "NOVA.md"), []byte("Root rules.\n"), 0644)
+	sub := filepath.Join(root, "service", "api")
+	os.MkdirAll(filepath.Join(root, "service", ".nova"), 0755)
+	os.MkdirAll(sub, 0755)
+	os.WriteFile(filepath.Join(root, "service", ".nova", "instructions.md"), []byte("Service rules.\n"), 0644)
+
+	sources, err := LoadInstructions(sub)
+	if err != nil {
+		t.Fatalf("LoadInstructions: %v", err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("loaded %d files, want 2: %+v", len(sources), sources)
+	}
+	if sources[0].Scope != "user" || sources[1].Scope != "project" || sources[1].Content != "Service rules.\n" {
+		t.Errorf("sources = %+v", sources)
+	}
+
+	text := FormatInstructions(sources)
+	if strings.Index(text, "Prefer tabs.") > strings.Index(text, "Service rules.") {
+		t.Errorf("project instructions should come last:\n%s", text)
+	}
+	if !strings.Contains(text, "# Project instructions (from ") {
+		t.Errorf("missing project heading:\n%s", text)
+	}
+}
+
+func TestLoadInstructionsSkipsEmptyAndRejectsLarge(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	workDir := t.TempDir()
+
+	os.WriteFile(filepath.Join(workDir, "NOVA.md"), []byte("  \n\n"), 0644)
+	sources, err := LoadInstructions(workDir)
+	if err != nil {
+		t.Fatalf("LoadInstructions: %v", err)
+	}
+	for _, src := range sources {
+		if src.Path == filepath.Join(workDir, "NOVA.md") {
+			t.Errorf("empty instructions file was loaded")
+		}
+	}
+
+	os.WriteFile(filepath.Join(workDir, "NOVA.md"), []byte(strings.Repeat("x", MaxInstructionsSize+1)), 0644)
+	if _, err := LoadInstructions(workDir); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Errorf("LoadInstructions error = %v, want too large", err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Keyring attributes identifying the API key in the Secret Service
+const (
+	keyringService = "nova-horizon"
+	keyringAccount = "api_key"
+)
+
+// keyringLookup reads the API key from the Secret Service (GNOME Keyring, KWallet, ...)
+// using libsecret's secret-tool. Store it with:
+//
+//	secret-tool store --label="Nova Horizon API key" service nova-horizon account api_key
+func keyringLookup() (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", errors.New("secret-tool not found (install libsecret-tools or libsecret)")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCmdTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	key := strings.TrimSpace(string(out))
+	if err != nil || key == "" {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("no secret found for service=%s account=%s: %s", keyringService, keyringAccount, msg)
+		}
+		return "", fmt.Errorf("no secret found for service=%s account=%s", keyringService, keyringAccount)
+	}
+	return key, nil
+}
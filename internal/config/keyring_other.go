@@ -0,0 +1,13 @@
+//go:build !linux
+
+package config
+
+import (
+	"errors"
+	"runtime"
+)
+
+// keyringLookup is only implemented for the Linux Secret Service; use api_key_cmd elsewhere
+func keyringLookup() (string, error) {
+	return "", errors.New("OS keyring lookup is not supported on " + runtime.GOOS + "; use api_key_cmd instead")
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const appName = "nova-horizon"
+
+// explicitConfigFile replaces the user config file when set (see SetConfigFile)
+var explicitConfigFile string
+
+// SetConfigFile makes path the user config file instead of the one in the config directory
+func SetConfigFile(path string) {
+	explicitConfigFile = path
+}
+
+// UserConfigPath returns the location of the user config file, whether or not it exists
+func UserConfigPath() string {
+	if explicitConfigFile != "" {
+		return explicitConfigFile
+	}
+	dir, err := configDir()
+	if err != nil {
+		return filepath.Join("~", ".config", appName, configFileName)
+	}
+	return filepath.Join(dir, configFileName)
+}
+
+// configDir returns the user-level configuration directory: $XDG_CONFIG_HOME/nova-horizon,
+// defaulting to ~/.config/nova-horizon
+func configDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// StateDir returns the directory for persistent state such as sessions and logs:
+// $XDG_STATE_HOME/nova-horizon, defaulting to ~/.local/state/nova-horizon
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// SessionsDir returns the directory holding per-session data
+func SessionsDir() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// LogDir returns the directory holding log files
+func LogDir() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs"), nil
+}
+
+// LogFilePath resolves a log.file value: ~ is expanded and relative paths are placed
+// in LogDir. An empty value stays empty (log to stderr).
+func LogFilePath(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	file = ExpandHome(file)
+	if filepath.IsAbs(file) {
+		return file, nil
+	}
+	dir, err := LogDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate log directory: %w", err)
+	}
+	return filepath.Join(dir, file), nil
+}
+
+// xdgDir resolves an XDG base directory for the app. Per the spec, relative values are ignored.
+func xdgDir(env string, fallback string) (string, error) {
+	if base := os.Getenv(env); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appName), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, fallback, appName), nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestXDGDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+
+	if got, want := UserConfigPath(), filepath.Join(home, ".config", appName, configFileName); got != want {
+		t.Errorf("UserConfigPath = %q, want %q", got, want)
+	}
+	if got, _ := LogDir(); got != filepath.Join(home, ".local", "state", appName, "logs") {
+		t.Errorf("LogDir = %q", got)
+	}
+
+	configHome, stateHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_STATE_HOME", stateHome)
+	if got, want := UserConfigPath(), filepath.Join(configHome, appName, configFileName); got != want {
+		t.Errorf("UserConfigPath = %q, want %q", got, want)
+	}
+	if got, _ := SessionsDir(); got != filepath.Join(stateHome, appName, "sessions") {
+		t.Errorf("SessionsDir = %q", got)
+	}
+
+	// Relative values are ignored, as the spec requires
+	t.Setenv("XDG_STATE_HOME", "relative/state")
+	if got, _ := StateDir(); got != filepath.Join(home, ".local", "state", appName) {
+		t.Errorf("StateDir = %q, want the default", got)
+	}
+}
+
+func TestLogFilePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	abs := filepath.Join(t.TempDir(), "nova.log")
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"", ""},
+		{abs, abs},
+		{"~/nova.log", filepath.Join(home, "nova.log")},
+		{"nova.log", filepath.Join(stateHome, appName, "logs", "nova.log")},
+	}
+	for _, tt := range tests {
+		if got, err := LogFilePath(tt.file); err != nil || got != tt.want {
+			t.Errorf("LogFilePath(%q) = %q, %v; want %q", tt.file, got, err, tt.want)
+		}
+	}
+}
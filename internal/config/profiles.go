@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+)
+
+// profileSet holds the [profiles.<name>] tables found in one config file
+type profileSet struct {
+	path     string
+	profiles map[string]*toml.Tree
+}
+
+// splitProfiles removes the [profiles] table from tree and validates every profile in it,
+// so a typo in an unused profile is still reported.
+func splitProfiles(tree *toml.Tree, path string) (profileSet, error) {
+	set := profileSet{path: path, profiles: make(map[string]*toml.Tree)}
+	if !tree.Has("profiles") {
+		return set, nil
+	}
+
+	table, ok := tree.Get("profiles").(*toml.Tree)
+	if !ok {
+		return set, fmt.Errorf("%s:%d: key \"profiles\": expected a table of [profiles.<name>] sections", path, tree.GetPosition("profiles").Line)
+	}
+
+	for _, name := range table.Keys() {
+		profile, ok := table.Get(name).(*toml.Tree)
+		if !ok {
+			return set, fmt.Errorf("%s:%d: key \"profiles.%s\": expected a [profiles.%s] section", path, table.GetPosition(name).Line, name, name)
+		}
+		if profile.Has("profile") || profile.Has("profiles") {
+			return set, fmt.Errorf("%s:%d: profile %q cannot select or define other profiles", path, profile.Position().Line, name)
+		}
+		if err := applyTree(Defaults(), profile, path, path); err != nil {
+			return set, fmt.Errorf("%w (in profile %q)", err, name)
+		}
+		set.profiles[name] = profile
+	}
+
+	if err := tree.Delete("profiles"); err != nil {
+		return set, fmt.Errorf("%s: %w", path, err)
+	}
+	return set, nil
+}
+
+// applyProfile applies the named profile from every file that defines it, lowest precedence first
+func applyProfile(cfg *Config, sets []profileSet, name string) error {
+	found := false
+	for _, set := range sets {
+		profile, ok := set.profiles[name]
+		if !ok {
+			continue
+		}
+		found = true
+		if err := applyTree(cfg, profile, set.path, fmt.Sprintf("%s [profiles.%s]", set.path, name)); err != nil {
+			return fmt.Errorf("%w (in profile %q)", err, name)
+		}
+	}
+
+	if !found {
+		available := profileNames(sets)
+		if len(available) == 0 {
+			return fmt.Errorf("profile %q not found: no [profiles.<name>] sections are defined", name)
+		}
+		return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(available, ", "))
+	}
+	return nil
+}
+
+// profileNames returns the sorted names of all profiles defined across sets
+func profileNames(sets []profileSet) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, set := range sets {
+		for name := range set.profiles {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
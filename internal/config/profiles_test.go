@@ -0,0 +1,77 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAppliesProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, env := range []string{"NOVA_PROFILE", "NOVA_MODEL", "NOVA_MAX_STEPS", "NOVA_VERBOSE"} {
+		t.Setenv(env, "")
+	}
+	writeConfig(t, UserConfigPath(), `
+model = "gemini-2.5-flash"
+
+[profiles.review]
+model = "gemini-2.5-pro"
+max_steps = 10
+`)
+	workDir := t.TempDir()
+	writeConfig(t, filepath.Join(workDir, ProjectConfigDir, configFileName), `
+max_steps = 30
+verbose = true
+
+[profiles.review]
+max_steps = 40
+`)
+
+	cfg, err := Load(workDir, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Model != "gemini-2.5-flash" || cfg.MaxSteps != 30 || cfg.Profile != "" {
+		t.Errorf("without a profile: model=%q max_steps=%d profile=%q", cfg.Model, cfg.MaxSteps, cfg.Profile)
+	}
+
+	// Profiles beat both files; the project's profile beats the user's
+	cfg, err = Load(workDir, "review")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Model != "gemini-2.5-pro" || cfg.MaxSteps != 40 || !cfg.Verbose || cfg.Profile != "review" {
+		t.Errorf("with profile: model=%q max_steps=%d verbose=%v profile=%q", cfg.Model, cfg.MaxSteps, cfg.Verbose, cfg.Profile)
+	}
+
+	t.Setenv("NOVA_PROFILE", "review")
+	if cfg, _ := Load(workDir, ""); cfg == nil || cfg.Profile != "review" || cfg.Sources["profile"] != "env NOVA_PROFILE" {
+		t.Errorf("NOVA_PROFILE not applied: %+v", cfg)
+	}
+}
+
+func TestLoadRejectsBadProfiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("NOVA_PROFILE", "")
+
+	tests := []struct {
+		name    string
+		content string
+		profile string
+		want    string
+	}{
+		{"unknown", "[profiles.fast]\nmodel = \"x\"\n[profiles.slow]\nmodel = \"y\"\n", "typo", `profile "typo" not found (available: fast, slow)`},
+		{"none defined", "model = \"x\"\n", "fast", "no [profiles.<name>] sections are defined"},
+		{"nested", "[profiles.fast]\nprofile = \"slow\"\n", "", "cannot select or define other profiles"},
+		{"unused typo", "[profiles.fast]\nmax_stepz = 3\n", "", `(in profile "fast")`},
+		{"not a table", "profiles = 3\n", "", "expected a table"},
+	}
+	for _, tt := range tests {
+		workDir := t.TempDir()
+		writeConfig(t, filepath.Join(workDir, ProjectConfigDir, configFileName), tt.content)
+		_, err := Load(workDir, tt.profile)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Load error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,504 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Kind is the value type of a configuration key
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	KindDuration
+	KindStringList
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindInt:
+		return "integer"
+	case KindBool:
+		return "boolean"
+	case KindDuration:
+		return "duration"
+	case KindStringList:
+		return "list of strings"
+	default:
+		return "unknown"
+	}
+}
+
+// Key describes one configuration key, how it is read and where it is stored in Config
+type Key struct {
+	Name        string // Dotted TOML path, e.g. "tools.exec_timeout"
+	Kind        Kind
+	Env         []string // Environment variables that override the key, in order of preference
+	Secret      bool
+	UserOnly    bool // Ignored in project config, e.g. because it runs a command or loosens a safeguard
+	Description string
+
+	get      func(*Config) interface{}
+	set      func(*Config, interface{})
+	validate func(interface{}) error
+}
+
+// Keys lists every supported configuration key
+var Keys = []*Key{
+	{
+		Name: "api_key", Kind: KindString, Env: []string{"GEMINI_API_KEY", "NOVA_API_KEY"}, Secret: true, UserOnly: true,
+		Description: "Gemini API key",
+		get:         func(c *Config) interface{} { return c.APIKey },
+		set:         func(c *Config, v interface{}) { c.APIKey = v.(string) },
+	},
+	{
+		Name: "api_key_env", Kind: KindString, UserOnly: true,
+		Description: "Environment variable to read the API key from (takes precedence over GEMINI_API_KEY)",
+		get:         func(c *Config) interface{} { return c.APIKeyEnv },
+		set:         func(c *Config, v interface{}) { c.APIKeyEnv = v.(string) },
+	},
+	{
+		Name: "api_key_cmd", Kind: KindString, Env: []string{"NOVA_API_KEY_CMD"}, UserOnly: true,
+		Description: "Command whose output is the API key (e.g. \"pass show gemini\")",
+		get:         func(c *Config) interface{} { return c.APIKeyCmd },
+		set:         func(c *Config, v interface{}) { c.APIKeyCmd = v.(string) },
+	},
+	{
+		Name: "api_key_keyring", Kind: KindBool, Env: []string{"NOVA_API_KEY_KEYRING"},
+		Description: "Read the API key from the OS keyring (Secret Service on Linux)",
+		get:         func(c *Config) interface{} { return c.APIKeyKeyring },
+		set:         func(c *Config, v interface{}) { c.APIKeyKeyring = v.(bool) },
+	},
+	{
+		Name: "provider", Kind: KindString,
+		Description: "Model provider (currently only \"gemini\")",
+		get:         func(c *Config) interface{} { return c.Provider },
+		set:         func(c *Config, v interface{}) { c.Provider = v.(string) },
+		validate:    supportedProvider,
+	},
+	{
+		Name: "profile", Kind: KindString,
+		Description: "Profile applied when neither --profile nor NOVA_PROFILE is set",
+		get:         func(c *Config) interface{} { return c.Profile },
+		set:         func(c *Config, v interface{}) { c.Profile = v.(string) },
+	},
+	{
+		Name: "model", Kind: KindString, Env: []string{"NOVA_MODEL"},
+		Description: "Model to use",
+		get:         func(c *Config) interface{} { return c.Model },
+		set:         func(c *Config, v interface{}) { c.Model = v.(string) },
+		validate:    nonEmpty,
+	},
+	{
+		Name: "work_dir", Kind: KindString, Env: []string{"NOVA_WORK_DIR"}, UserOnly: true,
+		Description: "Working directory (default: current directory)",
+		get:         func(c *Config) interface{} { return c.WorkDir },
+		set:         func(c *Config, v interface{}) { c.WorkDir = v.(string) },
+	},
+	{
+		Name: "verbose", Kind: KindBool, Env: []string{"NOVA_VERBOSE"},
+		Description: "Enable verbose output",
+		get:         func(c *Config) interface{} { return c.Verbose },
+		set:         func(c *Config, v interface{}) { c.Verbose = v.(bool) },
+	},
+	{
+		Name: "dry_run", Kind: KindBool, Env: []string{"NOVA_DRY_RUN"},
+		Description: "Simulate file changes in memory, skip running programs and show the resulting diff",
+		get:         func(c *Config) interface{} { return c.DryRun },
+		set:         func(c *Config, v interface{}) { c.DryRun = v.(bool) },
+	},
+	{
+		Name: "allow_run", Kind: KindBool, Env: []string{"NOVA_ALLOW_RUN"}, UserOnly: true,
+		Description: "Allow execution of programs",
+		get:         func(c *Config) interface{} { return c.AllowRun },
+		set:         func(c *Config, v interface{}) { c.AllowRun = v.(bool) },
+	},
+	{
+		Name: "apply", Kind: KindBool, Env: []string{"NOVA_APPLY"}, UserOnly: true,
+		Description: "Automatically apply file changes without confirmation",
+		get:         func(c *Config) interface{} { return c.ApplyDiff },
+		set:         func(c *Config, v interface{}) { c.ApplyDiff = v.(bool) },
+	},
+	{
+		Name: "plan", Kind: KindBool, Env: []string{"NOVA_PLAN"},
+		Description: "Propose a plan with read-only tools and wait for approval before changing anything",
+		get:         func(c *Config) interface{} { return c.Plan },
+		set:         func(c *Config, v interface{}) { c.Plan = v.(bool) },
+	},
+	{
+		Name: "read_only", Kind: KindBool, Env: []string{"NOVA_READ_ONLY"},
+		Description: "Answer questions about the code without changing files or running programs",
+		get:         func(c *Config) interface{} { return c.ReadOnly },
+		set:         func(c *Config, v interface{}) { c.ReadOnly = v.(bool) },
+	},
+	{
+		Name: "max_steps", Kind: KindInt, Env: []string{"NOVA_MAX_STEPS"},
+		Description: "Maximum agent loop iterations",
+		get:         func(c *Config) interface{} { return c.MaxSteps },
+		set:         func(c *Config, v interface{}) { c.MaxSteps = v.(int) },
+		validate:    positive,
+	},
+	{
+		Name: "max_retries", Kind: KindInt, Env: []string{"NOVA_MAX_RETRIES"},
+		Description: "Maximum retries for rate-limited or failed API calls",
+		get:         func(c *Config) interface{} { return c.MaxRetries },
+		set:         func(c *Config, v interface{}) { c.MaxRetries = v.(int) },
+		validate:    nonNegative,
+	},
+	{
+		Name: "context_limit", Kind: KindInt, Env: []string{"NOVA_CONTEXT_LIMIT"},
+		Description: "Token count at which older conversation history is summarized (0 disables)",
+		get:         func(c *Config) interface{} { return c.ContextLimit },
+		set:         func(c *Config, v interface{}) { c.ContextLimit = v.(int) },
+		validate:    nonNegative,
+	},
+	{
+		Name: "system_prompt", Kind: KindString,
+		Description: "Template replacing the built-in system prompt",
+		get:         func(c *Config) interface{} { return c.SystemPrompt },
+		set:         func(c *Config, v interface{}) { c.SystemPrompt = v.(string) },
+	},
+	{
+		Name: "system_prompt_append", Kind: KindString,
+		Description: "Template appended to the system prompt",
+		get:         func(c *Config) interface{} { return c.SystemPromptAppend },
+		set:         func(c *Config, v interface{}) { c.SystemPromptAppend = v.(string) },
+	},
+	{
+		Name: "log.level", Kind: KindString, Env: []string{"NOVA_LOG_LEVEL"},
+		Description: "Minimum log level: debug, info, warn or error",
+		get:         func(c *Config) interface{} { return c.Log.Level },
+		set:         func(c *Config, v interface{}) { c.Log.Level = v.(string) },
+		validate:    oneOf("debug", "info", "warn", "error"),
+	},
+	{
+		Name: "log.format", Kind: KindString, Env: []string{"NOVA_LOG_FORMAT"},
+		Description: "Log format: text or json",
+		get:         func(c *Config) interface{} { return c.Log.Format },
+		set:         func(c *Config, v interface{}) { c.Log.Format = v.(string) },
+		validate:    oneOf("text", "json"),
+	},
+	{
+		Name: "log.file", Kind: KindString, Env: []string{"NOVA_LOG_FILE"}, UserOnly: true,
+		Description: "Write logs to this file instead of stderr (relative paths are in the log directory)",
+		get:         func(c *Config) interface{} { return c.Log.File },
+		set:         func(c *Config, v interface{}) { c.Log.File = v.(string) },
+	},
+	{
+		Name: "git.enabled", Kind: KindBool, Env: []string{"NOVA_GIT"}, UserOnly: true,
+		Description: "Guard against dirty trees and commit agent changes when in a git repository",
+		get:         func(c *Config) interface{} { return c.Git.Enabled },
+		set:         func(c *Config, v interface{}) { c.Git.Enabled = v.(bool) },
+	},
+	{
+		Name: "git.allow_dirty", Kind: KindBool, Env: []string{"NOVA_ALLOW_DIRTY"}, UserOnly: true,
+		Description: "Start even if the working tree has uncommitted changes",
+		get:         func(c *Config) interface{} { return c.Git.AllowDirty },
+		set:         func(c *Config, v interface{}) { c.Git.AllowDirty = v.(bool) },
+	},
+	{
+		Name: "git.auto_commit", Kind: KindBool,
+		Description: "Commit the agent's changes after each successful step",
+		get:         func(c *Config) interface{} { return c.Git.AutoCommit },
+		set:         func(c *Config, v interface{}) { c.Git.AutoCommit = v.(bool) },
+	},
+	{
+		Name: "git.branch", Kind: KindString, Env: []string{"NOVA_GIT_BRANCH"},
+		Description: "Branch to switch to (created if missing) before the agent changes anything",
+		get:         func(c *Config) interface{} { return c.Git.Branch },
+		set:         func(c *Config, v interface{}) { c.Git.Branch = v.(string) },
+	},
+	{
+		Name: "tools.exec_timeout", Kind: KindDuration, Env: []string{"NOVA_EXEC_TIMEOUT"},
+		Description: "Time limit for run_file (e.g. \"30s\", \"2m\")",
+		get:         func(c *Config) interface{} { return c.Tools.ExecTimeout },
+		set:         func(c *Config, v interface{}) { c.Tools.ExecTimeout = v.(time.Duration) },
+		validate:    positiveDuration,
+	},
+	{
+		Name: "tools.max_file_size", Kind: KindInt, Env: []string{"NOVA_MAX_FILE_SIZE"},
+		Description: "Largest file (in bytes) the agent may read or write",
+		get:         func(c *Config) interface{} { return c.Tools.MaxFileSize },
+		set:         func(c *Config, v interface{}) { c.Tools.MaxFileSize = v.(int) },
+		validate:    positive,
+	},
+	{
+		Name: "tools.run_extensions", Kind: KindStringList, UserOnly: true,
+		Description: "File extensions run_file may execute",
+		get:         func(c *Config) interface{} { return c.Tools.RunExtensions },
+		set:         func(c *Config, v interface{}) { c.Tools.RunExtensions = v.([]string) },
+		validate:    extensions,
+	},
+	{
+		Name: "tools.write_extensions", Kind: KindStringList, UserOnly: true,
+		Description: "File extensions write_file may create or change (\"\" allows files without one; empty list allows all)",
+		get:         func(c *Config) interface{} { return c.Tools.WriteExtensions },
+		set:         func(c *Config, v interface{}) { c.Tools.WriteExtensions = v.([]string) },
+		validate:    extensions,
+	},
+	{
+		Name: "tools.protected_paths", Kind: KindStringList, UserOnly: true,
+		Description: "Globs write_file may never change (e.g. \".git/**\", \"go.sum\")",
+		get:         func(c *Config) interface{} { return c.Tools.ProtectedPaths },
+		set:         func(c *Config, v interface{}) { c.Tools.ProtectedPaths = v.([]string) },
+		validate:    globs,
+	},
+	{
+		Name: "tools.max_files_written", Kind: KindInt, Env: []string{"NOVA_MAX_FILES_WRITTEN"}, UserOnly: true,
+		Description: "Most distinct files one run may write (0 for no limit)",
+		get:         func(c *Config) interface{} { return c.Tools.MaxFilesWritten },
+		set:         func(c *Config, v interface{}) { c.Tools.MaxFilesWritten = v.(int) },
+		validate:    nonNegative,
+	},
+	{
+		Name: "tools.create_dirs", Kind: KindBool, UserOnly: true,
+		Description: "Allow write_file to create new directories",
+		get:         func(c *Config) interface{} { return c.Tools.CreateDirs },
+		set:         func(c *Config, v interface{}) { c.Tools.CreateDirs = v.(bool) },
+	},
+}
+
+// LookupKey returns the schema entry for a dotted key name
+func LookupKey(name string) (*Key, bool) {
+	for _, k := range Keys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the key's current value in cfg
+func (k *Key) Get(cfg *Config) interface{} {
+	return k.get(cfg)
+}
+
+// Set stores an already-typed value in cfg after validating it
+func (k *Key) Set(cfg *Config, v interface{}) error {
+	if k.validate != nil {
+		if err := k.validate(v); err != nil {
+			return err
+		}
+	}
+	k.set(cfg, v)
+	return nil
+}
+
+// Format renders a value of this key for display
+func (k *Key) Format(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Duration:
+		return val.String()
+	case []string:
+		return strings.Join(val, ",")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// ParseString converts a string (from an environment variable or the command line) to the key's type
+func (k *Key) ParseString(s string) (interface{}, error) {
+	switch k.Kind {
+	case KindString:
+		return s, nil
+	case KindInt:
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer, got %q", s)
+		}
+		return n, nil
+	case KindBool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false, got %q", s)
+		}
+		return b, nil
+	case KindDuration:
+		return parseDuration(strings.TrimSpace(s))
+	case KindStringList:
+		var list []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unsupported type")
+}
+
+// fromTOML converts a value decoded by go-toml to the key's type
+func (k *Key) fromTOML(v interface{}) (interface{}, error) {
+	switch k.Kind {
+	case KindString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case KindInt:
+		if n, ok := v.(int64); ok {
+			return int(n), nil
+		}
+	case KindBool:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+	case KindDuration:
+		switch d := v.(type) {
+		case string:
+			return parseDuration(d)
+		case int64:
+			return time.Duration(d) * time.Second, nil
+		}
+	case KindStringList:
+		if items, ok := v.([]interface{}); ok {
+			list := make([]string, 0, len(items))
+			for _, item := range items {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected a value of type %s", k.Kind)
+				}
+				list = append(list, s)
+			}
+			return list, nil
+		}
+		if items, ok := v.([]string); ok {
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("expected a value of type %s", k.Kind)
+}
+
+// toTOML converts a typed value into something go-toml can encode
+func (k *Key) toTOML(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int:
+		return int64(val)
+	case time.Duration:
+		return val.String()
+	default:
+		return val
+	}
+}
+
+// applyTree sets every key present in tree on cfg, recording source as where each key came from.
+// Unknown keys and invalid values are reported with the file name, key and line number.
+func applyTree(cfg *Config, tree *toml.Tree, path string, source string) error {
+	for _, name := range flattenKeys(tree, "") {
+		key, ok := LookupKey(name)
+		if !ok {
+			return fmt.Errorf("%s:%d: unknown key %q", path, tree.GetPosition(name).Line, name)
+		}
+
+		v, err := key.fromTOML(tree.Get(name))
+		if err == nil {
+			err = key.Set(cfg, v)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: key %q: %w", path, tree.GetPosition(name).Line, name, err)
+		}
+		cfg.Sources[name] = source
+	}
+	return nil
+}
+
+// flattenKeys returns the dotted names of all leaf values in tree
+func flattenKeys(tree *toml.Tree, prefix string) []string {
+	var names []string
+	for _, k := range tree.Keys() {
+		name := prefix + k
+		if sub, ok := tree.Get(k).(*toml.Tree); ok {
+			names = append(names, flattenKeys(sub, name+".")...)
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("must be a duration like \"30s\" or \"2m\", got %q", s)
+	}
+	return d, nil
+}
+
+func supportedProvider(v interface{}) error {
+	if v.(string) != "gemini" {
+		return fmt.Errorf("unsupported provider %q (only \"gemini\" is available)", v)
+	}
+	return nil
+}
+
+func oneOf(allowed ...string) func(interface{}) error {
+	return func(v interface{}) error {
+		for _, a := range allowed {
+			if v.(string) == a {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), v)
+	}
+}
+
+func nonEmpty(v interface{}) error {
+	if v.(string) == "" {
+		return fmt.Errorf("must not be empty")
+	}
+	return nil
+}
+
+func positive(v interface{}) error {
+	if v.(int) <= 0 {
+		return fmt.Errorf("must be greater than zero, got %d", v)
+	}
+	return nil
+}
+
+func nonNegative(v interface{}) error {
+	if v.(int) < 0 {
+		return fmt.Errorf("must not be negative, got %d", v)
+	}
+	return nil
+}
+
+func positiveDuration(v interface{}) error {
+	if v.(time.Duration) <= 0 {
+		return fmt.Errorf("must be greater than zero")
+	}
+	return nil
+}
+
+func extensions(v interface{}) error {
+	for _, ext := range v.([]string) {
+		if ext != "" && !strings.HasPrefix(ext, ".") {
+			return fmt.Errorf("extension %q must start with a dot", ext)
+		}
+	}
+	return nil
+}
+
+func globs(v interface{}) error {
+	for _, pattern := range v.([]string) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const apiKeyCmdTimeout = 10 * time.Second
+
+// resolveAPIKey fills cfg.APIKey from api_key_cmd or the keyring unless the key already
+// came from an environment variable
+func resolveAPIKey(cfg *Config) error {
+	if strings.HasPrefix(cfg.Sources["api_key"], "env ") {
+		return nil
+	}
+
+	if cfg.APIKeyCmd != "" {
+		slog.Debug("reading API key from api_key_cmd", "command", cfg.APIKeyCmd)
+		key, err := runAPIKeyCmd(cfg.APIKeyCmd)
+		if err != nil {
+			return err
+		}
+		cfg.APIKey = key
+		cfg.Sources["api_key"] = "api_key_cmd"
+		return nil
+	}
+
+	if cfg.APIKeyKeyring {
+		slog.Debug("reading API key from keyring")
+		key, err := keyringLookup()
+		if err != nil {
+			return fmt.Errorf("failed to read API key from keyring: %w", err)
+		}
+		cfg.APIKey = key
+		cfg.Sources["api_key"] = "keyring"
+	}
+
+	return nil
+}
+
+// runAPIKeyCmd runs command through the shell and returns its trimmed standard output
+func runAPIKeyCmd(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCmdTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Stdin = os.Stdin // Allow e.g. gpg-agent pinentry prompts
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("api_key_cmd %q failed: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("api_key_cmd %q failed: %w", command, err)
+	}
+
+	// Like pass(1), treat only the first line as the secret
+	key, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("api_key_cmd %q printed nothing", command)
+	}
+	return key, nil
+}
+
+// checkSecretPermissions returns a warning if a file holding secrets is readable by other users
+func checkSecretPermissions(path string) string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	if mode := info.Mode().Perm(); mode&0044 != 0 {
+		return fmt.Sprintf("%s contains an api_key but is readable by other users (mode %04o); run: chmod 600 %s", path, mode, path)
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunAPIKeyCmd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands below need a POSIX shell")
+	}
+
+	key, err := runAPIKeyCmd(`printf '  key-123  \nsecond line\n'`)
+	if err != nil || key != "key-123" {
+		t.Errorf("runAPIKeyCmd = %q, %v; want the first line", key, err)
+	}
+
+	if _, err := runAPIKeyCmd("echo locked >&2; exit 1"); err == nil || !strings.Contains(err.Error(), "locked") {
+		t.Errorf("failing command error = %v, want its stderr", err)
+	}
+	if _, err := runAPIKeyCmd("true"); err == nil || !strings.Contains(err.Error(), "printed nothing") {
+		t.Errorf("silent command error = %v", err)
+	}
+}
+
+func TestResolveAPIKey(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands below need a POSIX shell")
+	}
+
+	cfg := Defaults()
+	cfg.APIKey = "from-file"
+	cfg.APIKeyCmd = "echo from-cmd"
+	if err := resolveAPIKey(cfg); err != nil || cfg.APIKey != "from-cmd" || cfg.Sources["api_key"] != "api_key_cmd" {
+		t.Errorf("api_key = %q from %q, %v; want the command's output", cfg.APIKey, cfg.Sources["api_key"], err)
+	}
+
+	// A key from the environment wins, so the command is not run
+	cfg = Defaults()
+	cfg.APIKey = "from-env"
+	cfg.Sources["api_key"] = "env GEMINI_API_KEY"
+	cfg.APIKeyCmd = "exit 1"
+	if err := resolveAPIKey(cfg); err != nil || cfg.APIKey != "from-env" {
+		t.Errorf("api_key = %q, %v; want the environment's key", cfg.APIKey, err)
+	}
+}
+
+func TestCheckSecretPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not checked on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("api_key = \"k\"\n"), 0600)
+	if warning := checkSecretPermissions(path); warning != "" {
+		t.Errorf("mode 0600 warned: %s", warning)
+	}
+	os.Chmod(path, 0644)
+	if warning := checkSecretPermissions(path); !strings.Contains(warning, "chmod 600") {
+		t.Errorf("mode 0644 warning = %q", warning)
+	}
+}
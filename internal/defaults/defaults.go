@@ -0,0 +1,27 @@
+// Package defaults holds the built-in tool limits, shared by the config schema and the
+// tools so that both start from the same values.
+package defaults
+
+import "time"
+
+const (
+	MaxFileSize     = 100000 // 100KB, the largest file the agent may read or write
+	ExecTimeout     = 30 * time.Second
+	MaxFilesWritten = 50 // Distinct files a single run may write
+)
+
+// RunExtensions are the file extensions run_file may execute
+var RunExtensions = []string{".go", ".py", ".sh", ".js", ".ts"}
+
+// WriteExtensions are the file extensions write_file may create or change;
+// the empty entry allows extensionless files such as Makefile
+var WriteExtensions = []string{
+	"", ".go", ".py", ".sh", ".js", ".ts", ".md", ".txt", ".json", ".yaml", ".yml", ".toml", ".env",
+	".html", ".css", ".jsx", ".tsx", ".sql", ".xml", ".mod",
+}
+
+// ProtectedPaths are globs write_file may never touch: VCS internals and lockfiles
+var ProtectedPaths = []string{
+	".git/**", "go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+	"Cargo.lock", "poetry.lock", "Pipfile.lock", "Gemfile.lock", "composer.lock",
+}
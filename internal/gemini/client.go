@@ -3,18 +3,28 @@ package gemini
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
 type GeminiClient struct {
-	client *genai.Client
-	model  string
+	client       *genai.Client
+	model        string
+	retry        RetryPolicy
+	systemPrompt string
 }
 
-func NewGeminiClient(apiKey string, model string) (*GeminiClient, error) {
-	cl, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+// StreamHandler receives text fragments as they arrive from the model
+type StreamHandler func(text string)
+
+func NewGeminiClient(ctx context.Context, apiKey string, model string) (*GeminiClient, error) {
+	cl, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
@@ -22,50 +32,149 @@ func NewGeminiClient(apiKey string, model string) (*GeminiClient, error) {
 	return &GeminiClient{
 		client: cl,
 		model:  model,
+		retry:  DefaultRetryPolicy(),
 	}, nil
 }
 
-func (gc *GeminiClient) GenerateContent(ctx context.Context, history []*genai.Content, tools []*genai.Tool) (*genai.GenerateContentResponse, error) {
+// SetRetryPolicy replaces the policy used for transient API failures
+func (gc *GeminiClient) SetRetryPolicy(p RetryPolicy) {
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+	gc.retry = p
+}
+
+// SetSystemPrompt sets the system instruction sent with every agent request
+func (gc *GeminiClient) SetSystemPrompt(prompt string) {
+	gc.systemPrompt = prompt
+}
+
+// GenerateContentStream sends the conversation to the model and streams the reply.
+// Text parts are passed to onText as they arrive; the merged response (including any
+// function calls assembled from the stream) is returned once the stream ends.
+// Rate-limit and server errors are retried per the client's RetryPolicy as long as
+// nothing has been streamed yet.
+func (gc *GeminiClient) GenerateContentStream(ctx context.Context, history []*genai.Content, tools []*genai.Tool, onText StreamHandler) (*genai.GenerateContentResponse, error) {
+	// Should not happen if agent loop provides prompt
+	if len(history) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	var resp *genai.GenerateContentResponse
+	err := gc.retry.withRetry(ctx, func() error {
+		streamed := false
+		var err error
+		resp, err = gc.streamOnce(ctx, history, tools, func(text string) {
+			streamed = true
+			if onText != nil {
+				onText(text)
+			}
+		})
+		// A retry would repeat text the caller has already shown
+		if streamed {
+			return stopRetrying(err)
+		}
+		return err
+	})
+	return resp, err
+}
+
+func (gc *GeminiClient) streamOnce(ctx context.Context, history []*genai.Content, tools []*genai.Tool, onText StreamHandler) (*genai.GenerateContentResponse, error) {
 	model := gc.client.GenerativeModel(gc.model)
 	model.Tools = tools
 	model.SetTemperature(0) // Default to deterministic
 
 	model.SystemInstruction = &genai.Content{
 		Parts: []genai.Part{
-			genai.Text(systemPrompt),
+			genai.Text(gc.systemPrompt),
 		},
 	}
 
 	cs := model.StartChat()
 
 	// Separate history and the last message (which is the new input)
-	if len(history) > 0 {
-		cs.History = history[:len(history)-1]
-		lastMsg := history[len(history)-1]
-		return cs.SendMessage(ctx, lastMsg.Parts...)
+	cs.History = append([]*genai.Content(nil), history[:len(history)-1]...)
+	lastMsg := history[len(history)-1]
+
+	start := time.Now()
+	slog.Debug("gemini request",
+		"model", gc.model,
+		"messages", len(history),
+		"tools", len(tools),
+		"input", describeParts(lastMsg.Parts))
+
+	iter := cs.SendMessageStream(ctx, lastMsg.Parts...)
+
+	// The SDK keeps only the first chunk's usage metadata when merging; the last one is the complete count
+	var usage *genai.UsageMetadata
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			slog.Debug("gemini request failed", "model", gc.model, "duration", time.Since(start), "error", err)
+			return iter.MergedResponse(), err
+		}
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+
+		for _, cand := range resp.Candidates {
+			if cand.Content == nil {
+				continue
+			}
+			for _, part := range cand.Content.Parts {
+				if text, ok := part.(genai.Text); ok && text != "" {
+					onText(string(text))
+				}
+			}
+		}
 	}
 
-	// Should not happen if agent loop provides prompt
-	return nil, fmt.Errorf("no messages provided")
+	merged := iter.MergedResponse()
+	if merged != nil && usage != nil {
+		merged.UsageMetadata = usage
+	}
+	logResponse(merged, time.Since(start))
+	return merged, nil
 }
 
-const systemPrompt = `You are a helpful AI coding agent.
-
-When a user asks a question or makes a request, make a function call plan. You can perform the following operations:
-
-- List files and directories
-- Read file contents
-- Write or modify files
-- Execute scripts and programs
+// GenerateText runs a single tool-free request with its own system instruction and returns the text reply
+func (gc *GeminiClient) GenerateText(ctx context.Context, instruction string, input string) (string, error) {
+	model := gc.client.GenerativeModel(gc.model)
+	model.SetTemperature(0)
+	model.SystemInstruction = &genai.Content{
+		Parts: []genai.Part{
+			genai.Text(instruction),
+		},
+	}
 
-All paths you provide should be relative to the working directory. You do not need to specify the working directory in your function calls as it is automatically injected for security reasons.
+	var resp *genai.GenerateContentResponse
+	err := gc.retry.withRetry(ctx, func() error {
+		slog.Debug("gemini text request", "model", gc.model, "input", logger.Truncate(input, logPreviewBytes))
+		var err error
+		resp, err = model.GenerateContent(ctx, genai.Text(input))
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
 
-Follow these guidelines:
-1. Make function calls to gather information first
-2. Plan your approach before making changes
-3. Provide clear feedback about what you're doing
-4. Show diffs before writing files
-5. Stop if you detect infinite loops (same call multiple times)`
+	var out strings.Builder
+	for _, cand := range resp.Candidates {
+		if cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			if text, ok := part.(genai.Text); ok {
+				out.WriteString(string(text))
+			}
+		}
+		break
+	}
+	return out.String(), nil
+}
 
 func (gc *GeminiClient) Close() error {
 	return gc.client.Close()
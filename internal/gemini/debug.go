@@ -0,0 +1,58 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// logPreviewBytes caps how much of each message part is written to debug logs
+const logPreviewBytes = 500
+
+// logResponse writes a debug record summarizing a completed response
+func logResponse(resp *genai.GenerateContentResponse, duration time.Duration) {
+	if !slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+
+	attrs := []any{"duration", duration}
+	if resp != nil && len(resp.Candidates) > 0 {
+		cand := resp.Candidates[0]
+		attrs = append(attrs, "finish_reason", cand.FinishReason.String())
+		if cand.Content != nil {
+			attrs = append(attrs, "output", describeParts(cand.Content.Parts))
+		}
+	}
+	if resp != nil && resp.UsageMetadata != nil {
+		attrs = append(attrs,
+			"prompt_tokens", resp.UsageMetadata.PromptTokenCount,
+			"output_tokens", resp.UsageMetadata.CandidatesTokenCount)
+	}
+	slog.Debug("gemini response", attrs...)
+}
+
+// describeParts renders message parts compactly for logging
+func describeParts(parts []genai.Part) string {
+	var out []string
+	for _, part := range parts {
+		switch p := part.(type) {
+		case genai.Text:
+			out = append(out, fmt.Sprintf("text(%q)", logger.Truncate(string(p), logPreviewBytes)))
+		case genai.FunctionCall:
+			args, _ := json.Marshal(p.Args)
+			out = append(out, fmt.Sprintf("call %s(%s)", p.Name, logger.Truncate(string(args), logPreviewBytes)))
+		case genai.FunctionResponse:
+			resp, _ := json.Marshal(p.Response)
+			out = append(out, fmt.Sprintf("result %s(%s)", p.Name, logger.Truncate(string(resp), logPreviewBytes)))
+		default:
+			out = append(out, fmt.Sprintf("%T", part))
+		}
+	}
+	return strings.Join(out, "; ")
+}
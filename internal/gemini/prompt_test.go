@@ -0,0 +1,52 @@
+package gemini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSystemPrompt(t *testing.T) {
+	data := PromptData{
+		WorkDir:       "/src/app",
+		OS:            "linux",
+		Arch:          "amd64",
+		Date:          "2024-05-01",
+		Tools:         DescribeTools(BuildTools(false)),
+		RunExtensions: []string{".py", ".sh"},
+		ExecTimeout:   "30s",
+	}
+
+	prompt, err := RenderSystemPrompt(DefaultSystemPrompt, data)
+	if err != nil {
+		t.Fatalf("RenderSystemPrompt: %v", err)
+	}
+	for _, want := range []string{"/src/app on linux/amd64", "2024-05-01", "- write_file: ", "run_file can execute .py, .sh files, with a 30s timeout, but the user is asked"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+	if strings.Contains(prompt, "dry run") {
+		t.Error("prompt mentions a dry run")
+	}
+
+	data.ReadOnly = true
+	data.Tools = DescribeTools(BuildTools(true))
+	prompt, _ = RenderSystemPrompt(DefaultSystemPrompt, data)
+	if !strings.Contains(prompt, `read-only "ask" mode`) || strings.Contains(prompt, "write_file") {
+		t.Errorf("read-only prompt:\n%s", prompt)
+	}
+}
+
+func TestRenderSystemPromptCustomTemplate(t *testing.T) {
+	prompt, err := RenderSystemPrompt("  Model {{.Model}} in {{.WorkDir}}\n", PromptData{Model: "gemini-test", WorkDir: "/w"})
+	if err != nil || prompt != "Model gemini-test in /w" {
+		t.Errorf("RenderSystemPrompt = %q, %v", prompt, err)
+	}
+
+	if _, err := RenderSystemPrompt("{{.Model", PromptData{}); err == nil || !strings.Contains(err.Error(), "invalid system prompt template") {
+		t.Errorf("parse error = %v", err)
+	}
+	if _, err := RenderSystemPrompt("{{.Unknown}}", PromptData{}); err == nil || !strings.Contains(err.Error(), "failed to render") {
+		t.Errorf("unknown field error = %v", err)
+	}
+}
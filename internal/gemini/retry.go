@@ -0,0 +1,140 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// RetryPolicy controls how transient API failures are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first one (1 disables retries)
+	BaseDelay   time.Duration // Delay before the first retry, doubled on each attempt
+	MaxDelay    time.Duration // Upper bound for a single delay
+
+	// OnRetry, if set, is called before sleeping ahead of the next attempt
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// stopError marks an error that withRetry must return without retrying
+type stopError struct{ err error }
+
+func (e *stopError) Error() string { return e.err.Error() }
+func (e *stopError) Unwrap() error { return e.err }
+
+// stopRetrying makes withRetry give up on err, e.g. once part of a reply has been streamed
+func stopRetrying(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &stopError{err: err}
+}
+
+// withRetry calls call until it succeeds, fails with an error that is not worth retrying,
+// or MaxAttempts is reached. The last error is returned.
+func (p RetryPolicy) withRetry(ctx context.Context, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := call()
+		var stop *stopError
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+		if err == nil || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		wait, ok := p.retryDelay(err, attempt)
+		if !ok {
+			return err
+		}
+		slog.Info("retrying gemini request", "attempt", attempt, "wait", wait, "error", err)
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, wait, err)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// retryDelay reports whether err is worth retrying and how long to wait before the given retry attempt
+func (p RetryPolicy) retryDelay(err error, attempt int) (time.Duration, bool) {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == http.StatusTooManyRequests, apiErr.Code >= 500:
+			if wait, ok := parseRetryAfter(apiErr.Header); ok {
+				return min(wait, p.MaxDelay), true
+			}
+			return p.backoff(attempt), true
+		default:
+			// Auth, invalid-argument, not-found and other client errors won't fix themselves
+			return 0, false
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return p.backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+// backoff computes a jittered exponential delay for the given retry attempt (starting at 1)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	// Pick uniformly from [delay/2, delay] so concurrent clients spread out
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package gemini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestRetryDelay(t *testing.T) {
+	p := DefaultRetryPolicy()
+	retryAfter := http.Header{"Retry-After": []string{"7"}}
+	longRetryAfter := http.Header{"Retry-After": []string{"3600"}}
+
+	tests := []struct {
+		name  string
+		err   error
+		retry bool
+		wait  time.Duration // Exact wait expected; 0 means any backoff delay
+	}{
+		{"nil", nil, false, 0},
+		{"rate limited", &googleapi.Error{Code: http.StatusTooManyRequests}, true, 0},
+		{"server error", &googleapi.Error{Code: http.StatusServiceUnavailable}, true, 0},
+		{"wrapped server error", fmt.Errorf("request: %w", &googleapi.Error{Code: http.StatusInternalServerError}), true, 0},
+		{"retry after", &googleapi.Error{Code: http.StatusTooManyRequests, Header: retryAfter}, true, 7 * time.Second},
+		{"retry after capped", &googleapi.Error{Code: http.StatusTooManyRequests, Header: longRetryAfter}, true, p.MaxDelay},
+		{"bad request", &googleapi.Error{Code: http.StatusBadRequest}, false, 0},
+		{"unauthorized", &googleapi.Error{Code: http.StatusUnauthorized}, false, 0},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true, 0},
+		{"canceled", context.Canceled, false, 0},
+		{"deadline", fmt.Errorf("stream: %w", context.DeadlineExceeded), false, 0},
+		{"other", errors.New("invalid response"), false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := p.retryDelay(tt.err, 1)
+			if ok != tt.retry {
+				t.Fatalf("retry = %v, want %v", ok, tt.retry)
+			}
+			if tt.wait != 0 && wait != tt.wait {
+				t.Errorf("wait = %v, want %v", wait, tt.wait)
+			}
+		})
+	}
+}
+
+func TestBackoffSchedule(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 8, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{40, 10 * time.Second},
+		{70, 10 * time.Second}, // Shift overflow
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			if d := p.backoff(tt.attempt); d < tt.max/2 || d > tt.max {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", tt.attempt, d, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	serverErr := &googleapi.Error{Code: http.StatusServiceUnavailable}
+	badRequest := &googleapi.Error{Code: http.StatusBadRequest}
+	tests := []struct {
+		name     string
+		errs     []error // Returned by successive calls; nil after the list ends
+		calls    int
+		wantErr  error
+		attempts int
+	}{
+		{"success", nil, 1, nil, 3},
+		{"recovers", []error{serverErr, serverErr}, 3, nil, 3},
+		{"gives up", []error{serverErr, serverErr, serverErr}, 3, serverErr, 3},
+		{"permanent", []error{badRequest}, 1, badRequest, 3},
+		{"stopped", []error{stopRetrying(serverErr)}, 1, serverErr, 3},
+		{"no retries", []error{serverErr}, 1, serverErr, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var retries int
+			p := RetryPolicy{
+				MaxAttempts: tt.attempts,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    time.Millisecond,
+				OnRetry:     func(int, time.Duration, error) { retries++ },
+			}
+			calls := 0
+			err := p.withRetry(context.Background(), func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			if retries != calls-1 {
+				t.Errorf("OnRetry called %d times for %d calls", retries, calls)
+			}
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRetryStopsWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	p.OnRetry = func(int, time.Duration, error) { cancel() }
+
+	err := p.withRetry(ctx, func() error { return &googleapi.Error{Code: http.StatusTooManyRequests} })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
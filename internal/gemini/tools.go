@@ -4,16 +4,45 @@ import (
 	"github.com/google/generative-ai-go/genai"
 )
 
-// BuildTools creates tool definitions for the Gemini API
-func BuildTools() []*genai.Tool {
+// MutatingTools are the tools that change files or run programs
+var MutatingTools = map[string]bool{
+	"write_file":       true,
+	"delete_file":      true,
+	"move_file":        true,
+	"create_directory": true,
+	"run_file":         true,
+}
+
+// BuildTools creates tool definitions for the Gemini API. With readOnly set,
+// MutatingTools are left out.
+func BuildTools(readOnly bool) []*genai.Tool {
+	declarations := []*genai.FunctionDeclaration{
+		getFilesInfoSchema(),
+		gitStatusSchema(),
+		gitDiffSchema(),
+		gitLogSchema(),
+		gitBlameSchema(),
+		getFileContentSchema(),
+		writeFileSchema(),
+		deleteFileSchema(),
+		moveFileSchema(),
+		createDirectorySchema(),
+		runFileSchema(),
+	}
+
+	if readOnly {
+		var allowed []*genai.FunctionDeclaration
+		for _, fd := range declarations {
+			if !MutatingTools[fd.Name] {
+				allowed = append(allowed, fd)
+			}
+		}
+		declarations = allowed
+	}
+
 	return []*genai.Tool{
 		{
-			FunctionDeclarations: []*genai.FunctionDeclaration{
-				getFilesInfoSchema(),
-				getFileContentSchema(),
-				writeFileSchema(),
-				runFileSchema(),
-			},
+			FunctionDeclarations: declarations,
 		},
 	}
 }
@@ -72,6 +101,61 @@ func writeFileSchema() *genai.FunctionDeclaration {
 	}
 }
 
+func deleteFileSchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "delete_file",
+		Description: "Deletes a single file relative to the working directory. The user is always asked to confirm. Directories cannot be deleted.",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"file_path": {
+					Type:        genai.TypeString,
+					Description: "Path of the file to delete, relative to the working directory",
+				},
+			},
+			Required: []string{"file_path"},
+		},
+	}
+}
+
+func moveFileSchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "move_file",
+		Description: "Moves or renames a file within the working directory. Fails if the destination already exists.",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"source_path": {
+					Type:        genai.TypeString,
+					Description: "Current path of the file, relative to the working directory",
+				},
+				"destination_path": {
+					Type:        genai.TypeString,
+					Description: "New path of the file, relative to the working directory",
+				},
+			},
+			Required: []string{"source_path", "destination_path"},
+		},
+	}
+}
+
+func createDirectorySchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "create_directory",
+		Description: "Creates a directory (and any missing parents) relative to the working directory",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"directory": {
+					Type:        genai.TypeString,
+					Description: "Path of the directory to create, relative to the working directory",
+				},
+			},
+			Required: []string{"directory"},
+		},
+	}
+}
+
 func runFileSchema() *genai.FunctionDeclaration {
 	return &genai.FunctionDeclaration{
 		Name:        "run_file",
@@ -95,3 +179,83 @@ func runFileSchema() *genai.FunctionDeclaration {
 		},
 	}
 }
+
+func gitStatusSchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "git_status",
+		Description: "Shows the current git branch and the uncommitted (staged, unstaged and untracked) files in the working directory",
+		Parameters: &genai.Schema{
+			Type:       genai.TypeObject,
+			Properties: map[string]*genai.Schema{},
+		},
+	}
+}
+
+func gitDiffSchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "git_diff",
+		Description: "Shows a git diff of uncommitted changes in the working directory, of staged changes, or against a revision",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"path": {
+					Type:        genai.TypeString,
+					Description: "Optional file or directory to limit the diff to, relative to the working directory",
+				},
+				"ref": {
+					Type:        genai.TypeString,
+					Description: "Optional revision or range to compare, e.g. \"HEAD~1\" or \"main..HEAD\"",
+				},
+				"staged": {
+					Type:        genai.TypeBoolean,
+					Description: "Show staged changes instead of unstaged ones",
+				},
+			},
+		},
+	}
+}
+
+func gitLogSchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "git_log",
+		Description: "Lists recent git commits with the files they changed",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"path": {
+					Type:        genai.TypeString,
+					Description: "Optional file or directory; only commits touching it are listed",
+				},
+				"max_count": {
+					Type:        genai.TypeInteger,
+					Description: "Number of commits to show (default 10, at most 50)",
+				},
+			},
+		},
+	}
+}
+
+func gitBlameSchema() *genai.FunctionDeclaration {
+	return &genai.FunctionDeclaration{
+		Name:        "git_blame",
+		Description: "Shows the commit, author and date that last changed each line of a file",
+		Parameters: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"file_path": {
+					Type:        genai.TypeString,
+					Description: "Path of the file to blame, relative to the working directory",
+				},
+				"start_line": {
+					Type:        genai.TypeInteger,
+					Description: "Optional first line of the range to blame",
+				},
+				"end_line": {
+					Type:        genai.TypeInteger,
+					Description: "Optional last line of the range to blame",
+				},
+			},
+			Required: []string{"file_path"},
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package gemini
+
+import "testing"
+
+func TestBuildToolsReadOnly(t *testing.T) {
+	names := func(readOnly bool) map[string]bool {
+		got := map[string]bool{}
+		for _, tool := range BuildTools(readOnly) {
+			for _, fd := range tool.FunctionDeclarations {
+				got[fd.Name] = true
+			}
+		}
+		return got
+	}
+
+	all := names(false)
+	for name := range MutatingTools {
+		if !all[name] {
+			t.Errorf("%s missing from the full tool set", name)
+		}
+	}
+
+	readOnly := names(true)
+	for name := range MutatingTools {
+		if readOnly[name] {
+			t.Errorf("%s offered in read-only mode", name)
+		}
+	}
+	for _, name := range []string{"get_files_info", "get_file_content", "git_status", "git_diff", "git_log", "git_blame"} {
+		if !readOnly[name] {
+			t.Errorf("%s missing in read-only mode", name)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Repo runs git commands against a working directory inside a repository.
+// Everything is done by the local git binary; nothing touches the network.
+type Repo struct {
+	dir  string
+	root string
+
+	// exclude lists workdir-relative paths ignored by Dirty and CommitPaths
+	exclude []string
+}
+
+// Open returns the repository containing dir, or nil if dir is not in a git
+// work tree or git is not installed.
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		slog.Debug("git not found, git integration disabled")
+		return nil, nil
+	}
+
+	r := &Repo{dir: dir}
+	out, err := r.run(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		slog.Debug("not a git repository", "dir", dir)
+		return nil, nil
+	}
+	r.root = strings.TrimSpace(out)
+	return r, nil
+}
+
+// Root returns the top-level directory of the repository
+func (r *Repo) Root() string {
+	return r.root
+}
+
+// Exclude makes Dirty and CommitPaths ignore the given workdir-relative paths,
+// for files such as logs the tool itself maintains
+func (r *Repo) Exclude(paths ...string) {
+	r.exclude = append(r.exclude, paths...)
+}
+
+// pathspec limits a command to the working directory minus excluded paths
+func (r *Repo) pathspec() []string {
+	spec := []string{"--", "."}
+	for _, p := range r.exclude {
+		spec = append(spec, ":(exclude)"+p)
+	}
+	return spec
+}
+
+// Dirty returns the porcelain status lines of uncommitted changes in the working directory
+func (r *Repo) Dirty(ctx context.Context) ([]string, error) {
+	out, err := r.run(ctx, append([]string{"status", "--porcelain", "--untracked-files=all"}, r.pathspec()...)...)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
+
+// CurrentBranch returns the checked-out branch, or "" on a detached HEAD
+func (r *Repo) CurrentBranch(ctx context.Context) (string, error) {
+	out, err := r.run(ctx, "branch", "--show-current")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Checkout switches to branch, creating it from HEAD if it does not exist
+func (r *Repo) Checkout(ctx context.Context, branch string) error {
+	if _, err := r.run(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		_, err := r.run(ctx, "switch", branch)
+		return err
+	}
+	_, err := r.run(ctx, "switch", "-c", branch)
+	return err
+}
+
+// CommitPaths commits the current state of the given workdir-relative paths, and
+// nothing else: other changes in the work tree or index are left as they are.
+// It returns the new commit's short hash, or "" if there was nothing to commit.
+func (r *Repo) CommitPaths(ctx context.Context, message string, paths []string) (string, error) {
+	var spec []string
+	for _, p := range paths {
+		if r.excluded(p) {
+			continue
+		}
+		// Deleted files still need committing if git knows them
+		if _, err := os.Lstat(filepath.Join(r.dir, p)); err != nil {
+			out, err := r.run(ctx, "ls-files", "--", ":(literal)"+p)
+			if err != nil || strings.TrimSpace(out) == "" {
+				continue
+			}
+		}
+		spec = append(spec, ":(literal)"+p)
+	}
+	if len(spec) == 0 {
+		return "", nil
+	}
+	spec = append([]string{"--"}, spec...)
+
+	if _, err := r.run(ctx, append([]string{"add", "-A"}, spec...)...); err != nil {
+		return "", err
+	}
+
+	// diff --quiet exits 1 when there are staged changes
+	if _, err := r.run(ctx, append([]string{"diff", "--cached", "--quiet"}, spec...)...); err == nil {
+		return "", nil
+	}
+
+	// With paths, commit leaves anything else the user had staged out of the commit
+	if _, err := r.run(ctx, append([]string{"commit", "--quiet", "-m", message}, spec...)...); err != nil {
+		return "", err
+	}
+	out, err := r.run(ctx, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// excluded reports whether a workdir-relative path is one of the excluded paths
+func (r *Repo) excluded(path string) bool {
+	path = filepath.ToSlash(filepath.Clean(path))
+	for _, p := range r.exclude {
+		if path == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Output runs a git command in the working directory and returns its standard output.
+// Callers are responsible for passing only read-only commands.
+func (r *Repo) Output(ctx context.Context, args ...string) (string, error) {
+	return r.run(ctx, args...)
+}
+
+// run executes git in the working directory and returns its standard output
+func (r *Repo) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	// Never wait on credential or editor prompts, and don't refresh the index on reads
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true", "GIT_OPTIONAL_LOCKS=0")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	slog.Debug("ran git", "args", args, "exit_code", cmd.ProcessState.ExitCode())
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && stderr.Len() > 0 {
+			return stdout.String(), fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return stdout.String(), fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository with one commit containing a.txt and b.txt
+func newTestRepo(t *testing.T) (*Repo, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "init"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	r, err := Open(context.Background(), dir)
+	if err != nil || r == nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return r, dir
+}
+
+func TestCommitPathsLeavesOtherChanges(t *testing.T) {
+	r, dir := newTestRepo(t)
+	ctx := context.Background()
+
+	// The user's own edits, one of them staged
+	os.WriteFile(filepath.Join(dir, "b.txt"), []byte("user edit"), 0644)
+	os.WriteFile(filepath.Join(dir, "user.txt"), []byte("user"), 0644)
+	if _, err := r.run(ctx, "add", "user.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The agent's changes: an edit, a new file and a deletion
+	os.WriteFile(filepath.Join(dir, "new.txt"), []byte("agent"), 0644)
+	os.Remove(filepath.Join(dir, "a.txt"))
+
+	hash, err := r.CommitPaths(ctx, "nova: test", []string{"new.txt", "a.txt", "missing.txt"})
+	if err != nil {
+		t.Fatalf("CommitPaths: %v", err)
+	}
+	if hash == "" {
+		t.Fatal("nothing was committed")
+	}
+
+	out, err := r.run(ctx, "show", "--name-status", "--format=", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Fields(out), []string{"D", "a.txt", "A", "new.txt"}; strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("commit contains %v, want %v", got, want)
+	}
+
+	dirty, err := r.Dirty(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(dirty, "|"); got != " M b.txt|A  user.txt" {
+		t.Errorf("remaining changes = %q, want the user's edits untouched", got)
+	}
+}
+
+func TestCommitPathsSkipsExcludedAndUnchanged(t *testing.T) {
+	r, dir := newTestRepo(t)
+	ctx := context.Background()
+	r.Exclude(".nova/audit.jsonl")
+
+	os.MkdirAll(filepath.Join(dir, ".nova"), 0755)
+	os.WriteFile(filepath.Join(dir, ".nova", "audit.jsonl"), []byte("{}"), 0644)
+
+	hash, err := r.CommitPaths(ctx, "nova: test", []string{".nova/audit.jsonl", "a.txt"})
+	if err != nil {
+		t.Fatalf("CommitPaths: %v", err)
+	}
+	if hash != "" {
+		t.Errorf("committed %s with no agent changes", hash)
+	}
+}
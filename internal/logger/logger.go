@@ -2,36 +2,178 @@ package logger
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 )
 
-type Logger struct {
-	verbose bool
-	prefix  string
+const maskedValue = "********"
+
+// Options controls where and how log records are written
+type Options struct {
+	Level  string // debug, info, warn or error
+	Format string // text or json
+	File   string // Log file path; empty logs to stderr
 }
 
-func New(verbose bool) *Logger {
-	return &Logger{
-		verbose: verbose,
-		prefix:  "[nova-horizon]",
+var (
+	mu      sync.Mutex
+	logFile *os.File
+	secrets []string
+)
+
+// sensitiveKeys are attribute names whose values are always masked
+var sensitiveKeys = []string{"api_key", "apikey", "token", "password", "secret", "authorization"}
+
+func init() {
+	// Until Setup runs, only warnings and errors reach the terminal
+	if err := Setup(Options{}); err != nil {
+		panic(err)
 	}
 }
 
-func (l *Logger) Debug(msg string, args ...interface{}) {
-	if l.verbose {
-		log.Printf("%s DEBUG: %s\n", l.prefix, fmt.Sprintf(msg, args...))
+// Setup installs the default slog logger according to opts. A previously opened log
+// file is closed. Records never contain registered secrets (see AddSecret).
+func Setup(opts Options) error {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	var out io.Writer = os.Stderr
+	var file *os.File
+	if opts.File != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.File), 0700); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+		file, err = os.OpenFile(opts.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = file
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceAttr(file == nil),
 	}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(out, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		if file != nil {
+			file.Close()
+		}
+		return fmt.Errorf("unknown log format %q (expected text or json)", opts.Format)
+	}
+
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = file
+	slog.SetDefault(slog.New(handler))
+	return nil
 }
 
-func (l *Logger) Info(msg string, args ...interface{}) {
-	log.Printf("%s INFO: %s\n", l.prefix, fmt.Sprintf(msg, args...))
+// Close flushes and closes the log file, if any, and reverts to logging on stderr
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if logFile == nil {
+		return nil
+	}
+	err := logFile.Close()
+	logFile = nil
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level:       slog.LevelWarn,
+		ReplaceAttr: replaceAttr(true),
+	})))
+	return err
 }
 
-func (l *Logger) Error(msg string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, "%s ERROR: %s\n", l.prefix, fmt.Sprintf(msg, args...))
+// ParseLevel converts a level name to a slog.Level
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if s == "" {
+		return slog.LevelWarn, nil
+	}
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q (expected debug, info, warn or error)", s)
+	}
+	return level, nil
 }
 
-func (l *Logger) Warn(msg string, args ...interface{}) {
-	fmt.Printf("%s WARN: %s\n", l.prefix, fmt.Sprintf(msg, args...))
+// AddSecret registers a value that must never appear in log output
+func AddSecret(secret string) {
+	if len(secret) < 4 {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, s := range secrets {
+		if s == secret {
+			return
+		}
+	}
+	secrets = append(secrets, secret)
+}
+
+// Mask replaces every registered secret in s
+func Mask(s string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, maskedValue)
+	}
+	return s
+}
+
+// Truncate shortens s for logging, noting how much was cut
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return fmt.Sprintf("%s...[%d more bytes]", s[:max], len(s)-max)
+}
+
+// replaceAttr masks secrets in every attribute and, on a terminal, drops timestamps
+func replaceAttr(terminal bool) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if terminal && len(groups) == 0 && a.Key == slog.TimeKey {
+			return slog.Attr{}
+		}
+		return maskAttr(a)
+	}
+}
+
+func maskAttr(a slog.Attr) slog.Attr {
+	for _, k := range sensitiveKeys {
+		if strings.EqualFold(a.Key, k) {
+			return slog.String(a.Key, maskedValue)
+		}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, Mask(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, Mask(err.Error()))
+		}
+		if s, ok := a.Value.Any().(fmt.Stringer); ok {
+			return slog.String(a.Key, Mask(s.String()))
+		}
+	}
+	return a
 }
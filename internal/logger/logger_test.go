@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLogFileMasksSecrets(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "nova.log")
+	if err := Setup(Options{Level: "debug", Format: "json", File: path}); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	AddSecret("AIzaSecretValue")
+	AddSecret("abc") // Too short to mask safely
+
+	slog.Debug("request", "url", "https://example.com/?key=AIzaSecretValue", "api_key", "anything",
+		"error", errors.New("bad key AIzaSecretValue"), "note", "abc")
+	if err := Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "AIzaSecretValue") {
+		t.Errorf("secret in log: %s", data)
+	}
+	var record map[string]any
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("log is not JSON: %v", err)
+	}
+	for key, want := range map[string]string{
+		"url":     "https://example.com/?key=" + maskedValue,
+		"api_key": maskedValue,
+		"error":   "bad key " + maskedValue,
+		"note":    "abc",
+	} {
+		if record[key] != want {
+			t.Errorf("%s = %v, want %q", key, record[key], want)
+		}
+	}
+	if info, _ := os.Stat(path); runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		t.Errorf("log file mode = %v, want it private", info.Mode().Perm())
+	}
+}
+
+func TestSetupRejectsBadOptions(t *testing.T) {
+	defer Setup(Options{})
+	if err := Setup(Options{Level: "loud"}); err == nil {
+		t.Error("unknown level accepted")
+	}
+	if err := Setup(Options{Format: "xml"}); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]slog.Level{"": slog.LevelWarn, "debug": slog.LevelDebug, "INFO": slog.LevelInfo, "error": slog.LevelError} {
+		if got, err := ParseLevel(s); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+}
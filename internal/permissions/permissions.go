@@ -0,0 +1,403 @@
+package permissions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/brandnova/nova-horizon-cli/internal/config"
+	"github.com/brandnova/nova-horizon-cli/internal/tools"
+	"github.com/pelletier/go-toml"
+)
+
+// FileName is the project permission rules file kept in the project's .nova directory
+const FileName = "permissions.toml"
+
+// Action is what happens to a tool call a rule matches
+type Action string
+
+const (
+	Allow Action = "allow"
+	Ask   Action = "ask"
+	Deny  Action = "deny"
+)
+
+// Choice is the user's answer when a tool call needs permission
+type Choice int
+
+const (
+	Once Choice = iota
+	Session
+	Project
+	Refuse
+)
+
+// Rule applies Action to calls of Tool ("*" or empty for any tool) whose paths match
+// the Path glob and, for run_file, whose command line matches the Command pattern.
+// Empty Path and Command match anything.
+type Rule struct {
+	Tool    string `toml:"tool,omitempty"`
+	Path    string `toml:"path,omitempty"`
+	Command string `toml:"command,omitempty"`
+	Action  Action `toml:"action"`
+}
+
+// Request describes a tool call to be checked
+type Request struct {
+	Tool    string
+	Paths   []string // Workdir-relative, slash-separated
+	Command string   // The file and arguments, for run_file
+}
+
+// AlwaysAsk lists tools that always need the user's answer for the call at hand:
+// allow rules never apply to them, only deny rules
+var AlwaysAsk = map[string]bool{
+	"delete_file": true,
+}
+
+// Engine checks tool calls against session rules, project rules and per-tool defaults.
+// A matching deny rule always wins; otherwise the last matching session rule decides,
+// then the last matching project rule, then the tool's default.
+//
+// The project file comes with the repository, so its allow rules only apply once the
+// user has trusted its current content (see trust.go); deny and ask rules always apply.
+type Engine struct {
+	mu       sync.Mutex
+	file     string
+	project  []Rule
+	session  []Rule
+	defaults map[string]Action
+	trusted  bool
+}
+
+// RelPath returns the rules file location relative to the working directory
+func RelPath() string {
+	return filepath.Join(config.ProjectConfigDir, FileName)
+}
+
+// Defaults returns the default actions derived from --allow-run and --apply:
+// reads are allowed, deletes always ask, other changes and runs ask unless enabled
+func Defaults(allowRun bool, applyChanges bool) map[string]Action {
+	change, run := Ask, Ask
+	if applyChanges {
+		change = Allow
+	}
+	if allowRun {
+		run = Allow
+	}
+	return map[string]Action{
+		"write_file":       change,
+		"move_file":        change,
+		"create_directory": change,
+		"delete_file":      Ask,
+		"run_file":         run,
+	}
+}
+
+// Load reads workDir's project rules. A missing file means no project rules.
+func Load(workDir string) (*Engine, error) {
+	e := &Engine{
+		file:     filepath.Join(workDir, RelPath()),
+		defaults: Defaults(false, false),
+	}
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload re-reads the project rules file, keeping session rules
+func (e *Engine) Reload() error {
+	rules, err := readRules(e.file)
+	if err != nil {
+		return err
+	}
+	trusted, err := isTrusted(e.file)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.project = rules
+	e.trusted = trusted
+	e.mu.Unlock()
+	return nil
+}
+
+// SetDefaults replaces the per-tool default actions. Tools without a default are allowed.
+func (e *Engine) SetDefaults(defaults map[string]Action) {
+	e.mu.Lock()
+	e.defaults = defaults
+	e.mu.Unlock()
+}
+
+// Rules returns copies of the session and project rules
+func (e *Engine) Rules() (session []Rule, project []Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Rule(nil), e.session...), append([]Rule(nil), e.project...)
+}
+
+// File returns the path of the project rules file
+func (e *Engine) File() string {
+	return e.file
+}
+
+// Check decides a tool call. Each path is checked on its own and the most restrictive
+// result is returned, together with the deciding rule (nil for a default).
+func (e *Engine) Check(req Request) (Action, *Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	paths := req.Paths
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+
+	var action Action
+	var rule *Rule
+	for i, p := range paths {
+		a, r := e.check(req.Tool, p, req.Command)
+		if i == 0 || rank(a) > rank(action) {
+			action, rule = a, r
+		}
+	}
+	return action, rule
+}
+
+func (e *Engine) check(tool string, path string, command string) (Action, *Rule) {
+	for _, rules := range [][]Rule{e.session, e.project} {
+		for i := range rules {
+			if rules[i].Action == Deny && rules[i].matches(tool, path, command) {
+				return Deny, &rules[i]
+			}
+		}
+	}
+	if AlwaysAsk[tool] {
+		return Ask, nil
+	}
+	for layer, rules := range [][]Rule{e.session, e.project} {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if layer == 1 && !e.trusted && rules[i].Action == Allow {
+				continue
+			}
+			if rules[i].matches(tool, path, command) {
+				return rules[i].Action, &rules[i]
+			}
+		}
+	}
+	if action, ok := e.defaults[tool]; ok {
+		return action, nil
+	}
+	return Allow, nil
+}
+
+// Remember adds rules for the rest of the session, or saves them to the project file.
+// Saving keeps the file trusted only if it was trusted (or held no allow rules) before,
+// so answering a prompt never vouches for allow rules the user has not reviewed.
+func (e *Engine) Remember(rules []Rule, choice Choice) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	switch choice {
+	case Session:
+		e.session = append(e.session, rules...)
+	case Project:
+		// Re-read so that edits made since loading are never trusted on the user's behalf
+		current, err := readRules(e.file)
+		if err != nil {
+			return err
+		}
+		trusted, err := isTrusted(e.file)
+		if err != nil {
+			return err
+		}
+		keepTrust := trusted || !hasAllow(current)
+		if err := appendRules(e.file, rules); err != nil {
+			return err
+		}
+		e.project = append(e.project, rules...)
+		if keepTrust {
+			if err := trust(e.file); err != nil {
+				return err
+			}
+			e.trusted = true
+		}
+	}
+	return nil
+}
+
+// Trust makes the project file's allow rules apply for as long as its content is unchanged
+func (e *Engine) Trust() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := trust(e.file); err != nil {
+		return err
+	}
+	e.trusted = true
+	return nil
+}
+
+// Untrusted reports whether the project file has allow rules that are being ignored
+// because the user has not trusted its current content
+func (e *Engine) Untrusted() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.trusted && hasAllow(e.project)
+}
+
+func hasAllow(rules []Rule) bool {
+	for _, r := range rules {
+		if r.Action == Allow {
+			return true
+		}
+	}
+	return false
+}
+
+// RulesFor returns the rules that allow exactly the given call again: its command for
+// run_file, otherwise the tool on each of its paths
+func RulesFor(req Request) []Rule {
+	if req.Command != "" {
+		return []Rule{{Tool: req.Tool, Command: req.Command, Action: Allow}}
+	}
+	if len(req.Paths) == 0 {
+		return []Rule{{Tool: req.Tool, Action: Allow}}
+	}
+	rules := make([]Rule, 0, len(req.Paths))
+	for _, p := range req.Paths {
+		rules = append(rules, Rule{Tool: req.Tool, Path: p, Action: Allow})
+	}
+	return rules
+}
+
+// String formats a rule the way it is shown to the user, e.g. "allow write_file on src/**"
+func (r Rule) String() string {
+	tool := r.Tool
+	if tool == "" {
+		tool = "*"
+	}
+	s := string(r.Action) + " " + tool
+	if r.Path != "" {
+		s += " on " + r.Path
+	}
+	if r.Command != "" {
+		s += fmt.Sprintf(" command %q", r.Command)
+	}
+	return s
+}
+
+func (r Rule) matches(tool string, path string, command string) bool {
+	if r.Tool != "" && r.Tool != "*" && r.Tool != tool {
+		return false
+	}
+	if r.Path != "" && (path == "" || !tools.MatchGlob(r.Path, path)) {
+		return false
+	}
+	if r.Command != "" && !matchCommand(r.Command, command) {
+		return false
+	}
+	return true
+}
+
+// matchCommand matches a command line against a pattern in which "*" stands for
+// any text, including spaces and slashes
+func matchCommand(pattern string, command string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	re, err := regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+	return err == nil && re.MatchString(strings.TrimSpace(command))
+}
+
+// rank orders actions from least to most restrictive
+func rank(a Action) int {
+	switch a {
+	case Allow:
+		return 0
+	case Ask:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// rulesFile is the layout of permissions.toml: a list of [[rule]] tables
+type rulesFile struct {
+	Rules []Rule `toml:"rule"`
+}
+
+func readRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var file rulesFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for i, r := range file.Rules {
+		if err := validateRule(r); err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+	}
+	return file.Rules, nil
+}
+
+func validateRule(r Rule) error {
+	switch r.Action {
+	case Allow, Ask, Deny:
+	case "":
+		return fmt.Errorf("missing action (allow, ask or deny)")
+	default:
+		return fmt.Errorf("unknown action %q (use allow, ask or deny)", r.Action)
+	}
+	if r.Path != "" {
+		for _, segment := range strings.Split(filepath.ToSlash(r.Path), "/") {
+			if _, err := filepath.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid path glob %q: %w", r.Path, err)
+			}
+		}
+	}
+	if r.Command != "" && r.Tool != "run_file" && r.Tool != "" && r.Tool != "*" {
+		return fmt.Errorf("command only applies to run_file, not %s", r.Tool)
+	}
+	return nil
+}
+
+const fileHeader = `# Nova Horizon permission rules for this project.
+# Each [[rule]] sets the action (allow, ask or deny) for a tool, optionally limited to
+# a path glob or, for run_file, a command pattern where * matches anything.
+# A matching deny always wins; otherwise the last matching rule decides.
+`
+
+// appendRules adds rules to the end of the file, leaving existing rules and comments alone
+func appendRules(path string, rules []Rule) error {
+	data, err := toml.Marshal(rulesFile{Rules: rules})
+	if err != nil {
+		return fmt.Errorf("failed to encode permission rules: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		data = append([]byte(fileHeader), data...)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package permissions
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestEngine writes content as the project rules file and loads it, with trust
+// recorded in a temporary state directory
+func newTestEngine(t *testing.T, content string) *Engine {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	workDir := t.TempDir()
+	if content != "" {
+		path := filepath.Join(workDir, RelPath())
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e, err := Load(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+const allowAll = `
+[[rule]]
+tool = "*"
+action = "allow"
+`
+
+func TestDeleteAlwaysAsks(t *testing.T) {
+	e := newTestEngine(t, allowAll)
+	if err := e.Trust(); err != nil {
+		t.Fatal(err)
+	}
+	e.SetDefaults(Defaults(true, true))
+	if err := e.Remember([]Rule{{Tool: "delete_file", Action: Allow}}, Session); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := e.Check(Request{Tool: "delete_file", Paths: []string{"a.txt"}}); got != Ask {
+		t.Errorf("delete_file = %s, want ask", got)
+	}
+
+	if err := e.Remember([]Rule{{Tool: "delete_file", Path: "a.txt", Action: Deny}}, Session); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := e.Check(Request{Tool: "delete_file", Paths: []string{"a.txt"}}); got != Deny {
+		t.Errorf("denied delete_file = %s, want deny", got)
+	}
+}
+
+func TestUntrustedProjectAllowIgnored(t *testing.T) {
+	e := newTestEngine(t, allowAll+`
+[[rule]]
+tool = "write_file"
+path = "secret/**"
+action = "deny"
+`)
+	write := Request{Tool: "write_file", Paths: []string{"main.go"}}
+
+	if !e.Untrusted() {
+		t.Error("Untrusted() = false for a file that was never trusted")
+	}
+	if got, _ := e.Check(write); got != Ask {
+		t.Errorf("untrusted allow rule gave %s, want the default ask", got)
+	}
+	if got, _ := e.Check(Request{Tool: "write_file", Paths: []string{"secret/key"}}); got != Deny {
+		t.Errorf("untrusted deny rule gave %s, want deny", got)
+	}
+
+	if err := e.Trust(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := e.Check(write); got != Allow {
+		t.Errorf("trusted allow rule gave %s, want allow", got)
+	}
+
+	// Any edit to the file withdraws trust
+	f, err := os.OpenFile(e.File(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("# edited\n")
+	f.Close()
+	if err := e.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := e.Check(write); got != Ask {
+		t.Errorf("allow rule in edited file gave %s, want ask", got)
+	}
+}
+
+func TestRememberDoesNotTrustOthersRules(t *testing.T) {
+	e := newTestEngine(t, allowAll)
+	if err := e.Remember([]Rule{{Tool: "run_file", Command: "make test", Action: Allow}}, Project); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := e.Check(Request{Tool: "write_file", Paths: []string{"main.go"}}); got != Ask {
+		t.Errorf("write_file = %s after remembering a run rule, want ask", got)
+	}
+
+	// A file without allow rules stays trusted as the user's own answers are added
+	e = newTestEngine(t, "")
+	req := Request{Tool: "run_file", Command: "make test"}
+	if err := e.Remember(RulesFor(req), Project); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := e.Check(req); got != Allow {
+		t.Errorf("remembered run_file = %s, want allow", got)
+	}
+}
+
+const precedenceRules = `
+[[rule]]
+tool = "write_file"
+path = "src/**"
+action = "allow"
+
+[[rule]]
+tool = "write_file"
+path = "src/generated/**"
+action = "ask"
+
+[[rule]]
+tool = "*"
+path = "deploy/**"
+action = "deny"
+
+[[rule]]
+tool = "run_file"
+command = "scripts/test.sh *"
+action = "allow"
+`
+
+func TestCheckPrecedence(t *testing.T) {
+	e := newTestEngine(t, precedenceRules)
+	if err := e.Trust(); err != nil {
+		t.Fatal(err)
+	}
+	session := []Rule{
+		{Tool: "write_file", Path: "src/legacy/**", Action: Deny},
+		{Tool: "write_file", Path: "src/generated/api.go", Action: Allow},
+		{Tool: "write_file", Path: "deploy/notes.md", Action: Allow},
+	}
+	if err := e.Remember(session, Session); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		want Action
+	}{
+		{"project allow", Request{Tool: "write_file", Paths: []string{"src/main.go"}}, Allow},
+		{"later project rule wins", Request{Tool: "write_file", Paths: []string{"src/generated/db.go"}}, Ask},
+		{"session rule before project", Request{Tool: "write_file", Paths: []string{"src/generated/api.go"}}, Allow},
+		{"project deny beats session allow", Request{Tool: "write_file", Paths: []string{"deploy/notes.md"}}, Deny},
+		{"session deny beats project allow", Request{Tool: "write_file", Paths: []string{"src/legacy/old.go"}}, Deny},
+		{"wildcard tool deny", Request{Tool: "move_file", Paths: []string{"deploy/prod.yaml"}}, Deny},
+		{"most restrictive path", Request{Tool: "move_file", Paths: []string{"tmp/a", "deploy/a"}}, Deny},
+		{"default", Request{Tool: "write_file", Paths: []string{"README.md"}}, Ask},
+		{"tools without a default", Request{Tool: "get_file_content", Paths: []string{"README.md"}}, Allow},
+		{"command match", Request{Tool: "run_file", Command: "scripts/test.sh -v ./..."}, Allow},
+		{"command mismatch", Request{Tool: "run_file", Command: "scripts/deploy.sh"}, Ask},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := e.Check(tt.req); got != tt.want {
+				t.Errorf("Check = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckReturnsDecidingRule(t *testing.T) {
+	e := newTestEngine(t, precedenceRules)
+	if err := e.Trust(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, rule := e.Check(Request{Tool: "write_file", Paths: []string{"src/generated/db.go"}})
+	if rule == nil || rule.Path != "src/generated/**" {
+		t.Errorf("deciding rule = %v, want the src/generated/** rule", rule)
+	}
+	if _, rule := e.Check(Request{Tool: "write_file", Paths: []string{"README.md"}}); rule != nil {
+		t.Errorf("deciding rule = %v, want nil for a default", rule)
+	}
+}
@@ -0,0 +1,106 @@
+package permissions
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brandnova/nova-horizon-cli/internal/config"
+)
+
+// trustFileName records, in the user's state directory, the content hash of each
+// project rules file the user has trusted
+const trustFileName = "trusted-permissions.json"
+
+func trustPath() (string, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate state directory: %w", err)
+	}
+	return filepath.Join(dir, trustFileName), nil
+}
+
+// fileHash returns the sha256 of a file's content, or "" if it does not exist
+func fileHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func readTrusted() (map[string]string, error) {
+	path, err := trustPath()
+	if err != nil {
+		return nil, err
+	}
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return trusted, nil
+}
+
+// isTrusted reports whether the rules file's current content is the content the user trusted
+func isTrusted(file string) (bool, error) {
+	hash, err := fileHash(file)
+	if err != nil || hash == "" {
+		return false, err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return false, err
+	}
+	trusted, err := readTrusted()
+	if err != nil {
+		return false, err
+	}
+	return trusted[abs] == hash, nil
+}
+
+// trust records the rules file's current content as trusted by the user
+func trust(file string) error {
+	hash, err := fileHash(file)
+	if err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	trusted, err := readTrusted()
+	if err != nil {
+		return err
+	}
+	trusted[abs] = hash
+
+	path, err := trustPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,327 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brandnova/nova-horizon-cli/internal/config"
+)
+
+const manifestName = "snapshots.json"
+
+// Change is the state of one file before the agent touched it in a step
+type Change struct {
+	Step    int         `json:"step"`
+	Path    string      `json:"path"` // Relative to the session's workdir
+	Time    time.Time   `json:"time"`
+	Existed bool        `json:"existed"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+	Blob    string      `json:"blob,omitempty"` // File in the session directory holding the old content
+
+	// CreatedDir is the outermost parent directory that did not exist before the change
+	CreatedDir string `json:"created_dir,omitempty"`
+}
+
+// Manifest lists the changes recorded for a session
+type Manifest struct {
+	Session string    `json:"session"`
+	WorkDir string    `json:"work_dir"`
+	Started time.Time `json:"started"`
+	Changes []Change  `json:"changes"`
+}
+
+// Store keeps the previous content of every file changed in a session so the
+// changes can be rolled back. Nothing is written until the first Save.
+type Store struct {
+	mu       sync.Mutex
+	dir      string
+	manifest Manifest
+}
+
+// Restored describes what Undo did to one file
+type Restored struct {
+	Path    string
+	Deleted bool // The file was created by the agent and has been removed
+}
+
+// New returns an empty store for a session in workDir
+func New(session string, workDir string) (*Store, error) {
+	sessions, err := config.SessionsDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate session directory: %w", err)
+	}
+	return &Store{
+		dir: filepath.Join(sessions, session),
+		manifest: Manifest{
+			Session: session,
+			WorkDir: workDir,
+			Started: time.Now(),
+		},
+	}, nil
+}
+
+// Load opens the store of an existing session
+func Load(session string) (*Store, error) {
+	sessions, err := config.SessionsDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate session directory: %w", err)
+	}
+	s := &Store{dir: filepath.Join(sessions, session)}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, manifestName))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no file changes recorded for session %s", session)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %s: %w", session, err)
+	}
+	if err := json.Unmarshal(data, &s.manifest); err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest for session %s: %w", session, err)
+	}
+	return s, nil
+}
+
+// Sessions returns the manifests of sessions that changed files in workDir, newest first
+func Sessions(workDir string) ([]Manifest, error) {
+	sessions, err := config.SessionsDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate session directory: %w", err)
+	}
+	entries, err := os.ReadDir(sessions)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var manifests []Manifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(sessions, entry.Name(), manifestName))
+		if err != nil {
+			continue
+		}
+		var m Manifest
+		if json.Unmarshal(data, &m) != nil || m.WorkDir != workDir || len(m.Changes) == 0 {
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+
+	// Session ids start with their creation time
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Session > manifests[j].Session })
+	return manifests, nil
+}
+
+// Manifest returns a copy of the session's recorded changes
+func (s *Store) Manifest() Manifest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := s.manifest
+	m.Changes = append([]Change(nil), s.manifest.Changes...)
+	return m
+}
+
+// Save snapshots relPath before it is changed in step. Only the first change to a
+// file within a step is kept, since that holds the content from before the step.
+func (s *Store) Save(step int, relPath string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	for _, c := range s.manifest.Changes {
+		if c.Step == step && c.Path == relPath {
+			return nil
+		}
+	}
+
+	absPath := filepath.Join(s.manifest.WorkDir, relPath)
+	change := Change{Step: step, Path: relPath, Time: time.Now()}
+
+	info, err := os.Stat(absPath)
+	switch {
+	case err == nil:
+		if info.IsDir() {
+			// Existing directories are never changed, only created
+			return nil
+		}
+		content, err := os.ReadFile(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		if err := os.MkdirAll(s.dir, 0700); err != nil {
+			return fmt.Errorf("failed to create session directory: %w", err)
+		}
+		blob, err := os.CreateTemp(s.dir, "*.orig")
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot of %s: %w", relPath, err)
+		}
+		_, err = blob.Write(content)
+		if closeErr := blob.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save snapshot of %s: %w", relPath, err)
+		}
+		change.Existed = true
+		change.Mode = info.Mode().Perm()
+		change.Blob = filepath.Base(blob.Name())
+	case os.IsNotExist(err):
+		change.CreatedDir = s.missingDir(filepath.Dir(relPath))
+	default:
+		return fmt.Errorf("failed to inspect %s: %w", relPath, err)
+	}
+
+	s.manifest.Changes = append(s.manifest.Changes, change)
+	return s.writeManifest()
+}
+
+// missingDir returns the outermost directory of relDir that does not exist yet
+func (s *Store) missingDir(relDir string) string {
+	missing := ""
+	for dir := relDir; dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(s.manifest.WorkDir, dir)); err == nil {
+			break
+		}
+		missing = filepath.ToSlash(dir)
+	}
+	return missing
+}
+
+// Undo restores every file changed in step fromStep or later to its content from
+// before that step, deleting files the agent created. Undone changes are dropped
+// from the store, so Undo can be repeated to go further back.
+func (s *Store) Undo(fromStep int) ([]Restored, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The earliest snapshot of each file at or after fromStep is its state before that step
+	var kept []Change
+	earliest := make(map[string]Change)
+	var order []string
+	for _, c := range s.manifest.Changes {
+		if c.Step < fromStep {
+			kept = append(kept, c)
+			continue
+		}
+		if _, ok := earliest[c.Path]; !ok {
+			earliest[c.Path] = c
+			order = append(order, c.Path)
+		}
+	}
+	if len(order) == 0 {
+		return nil, fmt.Errorf("no file changes recorded at or after step %d", fromStep)
+	}
+
+	// Later changes are undone first, and files before directories, so directories
+	// the agent created are empty by the time they are removed
+	var files, dirs []string
+	for i := len(order) - 1; i >= 0; i-- {
+		if info, err := os.Lstat(filepath.Join(s.manifest.WorkDir, order[i])); err == nil && info.IsDir() {
+			dirs = append(dirs, order[i])
+		} else {
+			files = append(files, order[i])
+		}
+	}
+
+	// Every change is attempted; the manifest is only updated when all succeed, so a
+	// failed undo can be retried (restoring a snapshot twice does no harm)
+	var restored []Restored
+	var errs []error
+	for _, path := range append(files, dirs...) {
+		c := earliest[path]
+		kept, err := s.restore(c)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if kept {
+			continue
+		}
+		restored = append(restored, Restored{Path: path, Deleted: !c.Existed})
+	}
+	if len(errs) > 0 {
+		return restored, errors.Join(errs...)
+	}
+
+	// Snapshots of the undone steps are no longer needed
+	for _, c := range s.manifest.Changes[len(kept):] {
+		if c.Blob != "" {
+			os.Remove(filepath.Join(s.dir, c.Blob))
+		}
+	}
+	s.manifest.Changes = kept
+	return restored, s.writeManifest()
+}
+
+// restore puts one path back in its state before the change. kept reports a created
+// directory that was left in place because it is not empty.
+func (s *Store) restore(c Change) (kept bool, err error) {
+	absPath := filepath.Join(s.manifest.WorkDir, c.Path)
+
+	if !c.Existed {
+		// A directory the agent created is removed once its files are gone; anything
+		// else left in it (such as files the user added) keeps it in place
+		if info, err := os.Lstat(absPath); err == nil && info.IsDir() {
+			if entries, err := os.ReadDir(absPath); err == nil && len(entries) > 0 {
+				return true, nil
+			}
+		}
+		if err := os.Remove(absPath); err != nil && !os.IsNotExist(err) {
+			return false, fmt.Errorf("failed to delete %s: %w", c.Path, err)
+		}
+		// Remove directories the agent created for the file, as long as they are empty
+		if c.CreatedDir != "" {
+			stop := filepath.Dir(filepath.Join(s.manifest.WorkDir, c.CreatedDir))
+			for dir := filepath.Dir(absPath); dir != stop; dir = filepath.Dir(dir) {
+				if os.Remove(dir) != nil {
+					break
+				}
+			}
+		}
+		return false, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(s.dir, c.Blob))
+	if err != nil {
+		return false, fmt.Errorf("failed to read snapshot of %s: %w", c.Path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+		return false, fmt.Errorf("failed to create directories for %s: %w", c.Path, err)
+	}
+	if err := os.WriteFile(absPath, content, c.Mode); err != nil {
+		return false, fmt.Errorf("failed to restore %s: %w", c.Path, err)
+	}
+	if err := os.Chmod(absPath, c.Mode); err != nil {
+		return false, fmt.Errorf("failed to restore mode of %s: %w", c.Path, err)
+	}
+	return false, nil
+}
+
+func (s *Store) writeManifest() error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create session directory: %w", err)
+	}
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(s.dir, manifestName+".tmp")
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, manifestName)); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return nil
+}
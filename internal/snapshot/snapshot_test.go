@@ -0,0 +1,155 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestStore returns a store whose session data lives in a temporary state directory
+func newTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	workDir := t.TempDir()
+	s, err := New("test-session", workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, workDir
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUndoCreatedDirectoryWithFiles(t *testing.T) {
+	s, workDir := newTestStore(t)
+
+	if err := s.Save(1, "pkg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(workDir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(2, "pkg/a.go"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(workDir, "pkg", "a.go"), "package pkg\n")
+
+	restored, err := s.Undo(1)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if len(restored) != 2 || restored[0].Path != "pkg/a.go" || restored[1].Path != "pkg" {
+		t.Errorf("restored = %+v, want pkg/a.go then pkg", restored)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "pkg")); !os.IsNotExist(err) {
+		t.Errorf("pkg still exists after undo (err = %v)", err)
+	}
+	if n := len(s.Manifest().Changes); n != 0 {
+		t.Errorf("manifest has %d changes after undo, want 0", n)
+	}
+}
+
+func TestUndoKeepsCreatedDirectoryWithOtherFiles(t *testing.T) {
+	s, workDir := newTestStore(t)
+
+	if err := s.Save(1, "pkg"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(workDir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Added by the user, not the agent
+	writeFile(t, filepath.Join(workDir, "pkg", "notes.txt"), "mine")
+
+	restored, err := s.Undo(1)
+	if err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if len(restored) != 0 {
+		t.Errorf("restored = %+v, want nothing", restored)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "pkg", "notes.txt")); err != nil {
+		t.Errorf("user file removed: %v", err)
+	}
+}
+
+func TestUndoRestoresContentFromStep(t *testing.T) {
+	s, workDir := newTestStore(t)
+	path := filepath.Join(workDir, "main.go")
+	writeFile(t, path, "v1")
+
+	for step, content := range []string{"v2", "v3"} {
+		if err := s.Save(step+1, "main.go"); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, content)
+	}
+
+	if _, err := s.Undo(2); err != nil {
+		t.Fatalf("Undo(2): %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "v2" {
+		t.Errorf("after Undo(2) content = %q, want v2", got)
+	}
+	if n := len(s.Manifest().Changes); n != 1 {
+		t.Errorf("manifest has %d changes after Undo(2), want 1", n)
+	}
+
+	if _, err := s.Undo(1); err != nil {
+		t.Fatalf("Undo(1): %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "v1" {
+		t.Errorf("after Undo(1) content = %q, want v1", got)
+	}
+}
+
+func TestUndoFailureKeepsManifest(t *testing.T) {
+	s, workDir := newTestStore(t)
+	writeFile(t, filepath.Join(workDir, "a.txt"), "a")
+	writeFile(t, filepath.Join(workDir, "b.txt"), "b")
+
+	for _, p := range []string{"a.txt", "b.txt"} {
+		if err := s.Save(1, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Losing a snapshot makes its restore fail
+	for _, c := range s.Manifest().Changes {
+		if c.Path == "a.txt" {
+			os.Remove(filepath.Join(s.dir, c.Blob))
+		}
+	}
+	writeFile(t, filepath.Join(workDir, "b.txt"), "changed")
+
+	if _, err := s.Undo(1); err == nil {
+		t.Fatal("Undo succeeded with a missing snapshot")
+	}
+	if got, _ := os.ReadFile(filepath.Join(workDir, "b.txt")); string(got) != "b" {
+		t.Errorf("b.txt = %q, want it restored despite the other failure", got)
+	}
+	if n := len(s.Manifest().Changes); n != 2 {
+		t.Errorf("manifest has %d changes after failed undo, want 2", n)
+	}
+}
+
+func TestSaveIgnoresRepeatsWithinStep(t *testing.T) {
+	s, workDir := newTestStore(t)
+	writeFile(t, filepath.Join(workDir, "a.txt"), "a")
+
+	for i := 0; i < 2; i++ {
+		if err := s.Save(1, "a.txt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(s.Manifest().Changes); n != 1 {
+		t.Errorf("manifest has %d changes, want 1", n)
+	}
+}
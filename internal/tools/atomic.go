@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeFileAtomic replaces path with data so readers see either the old or the new
+// content, never a partial write: the data goes to a temporary file in the same
+// directory, is synced, and is renamed over path. An existing file's mode and
+// (where supported) ownership are kept; new files get perm. If the ownership
+// cannot be reproduced the file is overwritten in place.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	info, err := os.Stat(path)
+	exists := err == nil
+	if exists {
+		perm = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	// Clean up the temporary file on every failure path
+	defer func() {
+		if tmpName != "" {
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if exists {
+		if err := copyOwner(info, tmpName); err != nil {
+			// Only the owner's privileges could recreate the file as theirs, so
+			// keep ownership by overwriting in place instead
+			return os.WriteFile(path, data, perm)
+		}
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	tmpName = ""
+	syncDir(filepath.Dir(path))
+	return nil
+}
+
+// matchLineEndings rewrites content to use the original file's line endings
+// (LF or CRLF) and to end with a newline exactly when the original did
+func matchLineEndings(original string, content string) string {
+	if original == "" {
+		return content
+	}
+
+	crlf := strings.Count(original, "\r\n")
+	lf := strings.Count(original, "\n") - crlf
+	if crlf > 0 || lf > 0 {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+		if crlf > lf {
+			content = strings.ReplaceAll(content, "\n", "\r\n")
+		}
+	}
+
+	newline := "\n"
+	if crlf > lf {
+		newline = "\r\n"
+	}
+	hadTrailing := strings.HasSuffix(original, "\n")
+	hasTrailing := strings.HasSuffix(content, "\n")
+	switch {
+	case hadTrailing && !hasTrailing && content != "":
+		content += newline
+	case !hadTrailing && hasTrailing:
+		content = strings.TrimSuffix(strings.TrimSuffix(content, "\n"), "\r")
+	}
+	return content
+}
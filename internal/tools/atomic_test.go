@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestMatchLineEndings(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		content  string
+		want     string
+	}{
+		{"new file", "", "a\nb", "a\nb"},
+		{"lf kept", "a\nb\n", "a\nc\n", "a\nc\n"},
+		{"crlf restored", "a\r\nb\r\n", "a\nc\n", "a\r\nc\r\n"},
+		{"crlf not doubled", "a\r\nb\r\n", "a\r\nc\r\n", "a\r\nc\r\n"},
+		{"lf restored", "a\nb\n", "a\r\nc\r\n", "a\nc\n"},
+		{"trailing newline added", "a\n", "b", "b\n"},
+		{"trailing crlf added", "a\r\n", "b", "b\r\n"},
+		{"trailing newline dropped", "a", "b\n", "b"},
+		{"trailing crlf dropped", "a\r\nb", "a\r\nc\r\n", "a\r\nc"},
+		{"emptied file", "a\n", "", ""},
+	}
+	for _, tt := range tests {
+		if got := matchLineEndings(tt.original, tt.content); got != tt.want {
+			t.Errorf("%s: matchLineEndings(%q, %q) = %q, want %q", tt.name, tt.original, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not kept on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "run.sh")
+	os.WriteFile(path, []byte("old"), 0644)
+	os.Chmod(path, 0750)
+
+	if err := writeFileAtomic(path, []byte("new"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	info, _ := os.Stat(path)
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("mode = %v, want 0750", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("content = %q, want %q", data, "new")
+	}
+
+	// No temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want 1", len(entries))
+	}
+}
+
+func TestWriteFileAtomicNewFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new.txt")
+	if err := writeFileAtomic(path, []byte("hello"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "hello" {
+		t.Errorf("content = %q, want %q", data, "hello")
+	}
+}
+
+func TestWriteFileKeepsLineEndings(t *testing.T) {
+	workDir := t.TempDir()
+	os.WriteFile(filepath.Join(workDir, "notes.txt"), []byte("one\r\ntwo\r\n"), 0644)
+
+	tm := NewToolManager(workDir, false)
+	if _, err := tm.WriteFile("notes.txt", "one\nthree"); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(workDir, "notes.txt"))
+	if string(data) != "one\r\nthree\r\n" {
+		t.Errorf("content = %q, want CRLF endings and a trailing newline", data)
+	}
+}
@@ -7,58 +7,210 @@ import (
 	"github.com/fatih/color"
 )
 
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// maxDiffCells bounds the line-matching table; larger changes are shown as a
+// single replacement of the differing middle section
+const maxDiffCells = 4000000
+
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	line string
+}
+
 // GenerateDiff creates a unified diff between old and new content
 func (tm *ToolManager) GenerateDiff(oldContent, newContent string) string {
-	oldLines := strings.Split(oldContent, "\n")
-	newLines := strings.Split(newContent, "\n")
+	return "--- original\n+++ modified\n" + diffHunks(oldContent, newContent)
+}
 
-	var diff strings.Builder
-	diff.WriteString("--- original\n")
-	diff.WriteString("+++ modified\n")
+// FormatPatch renders file changes as a git-style patch that `git apply` accepts
+func FormatPatch(changes []FileChange) string {
+	var b strings.Builder
+	for _, c := range changes {
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n", c.Path, c.Path)
+		oldName, newName := "a/"+c.Path, "b/"+c.Path
+		switch {
+		case !c.OldExists:
+			b.WriteString("new file mode 100644\n")
+			oldName = "/dev/null"
+		case !c.NewExists:
+			b.WriteString("deleted file mode 100644\n")
+			newName = "/dev/null"
+		}
 
-	// Simple line-by-line diff (can be enhanced with proper diff algorithm)
-	maxLen := len(oldLines)
-	if len(newLines) > maxLen {
-		maxLen = len(newLines)
+		if isBinary(c.Old) || isBinary(c.New) {
+			fmt.Fprintf(&b, "Binary files %s and %s differ\n", oldName, newName)
+			continue
+		}
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+		b.WriteString(diffHunks(string(c.Old), string(c.New)))
 	}
+	return b.String()
+}
 
-	for i := 0; i < maxLen; i++ {
-		oldLine := ""
-		newLine := ""
+// diffHunks returns the @@ hunks of a unified diff between two texts
+func diffHunks(oldText, newText string) string {
+	ops := diffLines(splitLines(oldText), splitLines(newText))
 
-		if i < len(oldLines) {
-			oldLine = oldLines[i]
+	// oldPos[i] and newPos[i] count the lines of each side before ops[i]
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldPos[i+1], newPos[i+1] = oldPos[i], newPos[i]
+		if op.kind != '+' {
+			oldPos[i+1]++
 		}
-		if i < len(newLines) {
-			newLine = newLines[i]
+		if op.kind != '-' {
+			newPos[i+1]++
 		}
+	}
 
-		if oldLine != newLine {
-			if oldLine != "" {
-				diff.WriteString(fmt.Sprintf("-%s\n", oldLine))
+	var b strings.Builder
+	for i := 0; i < len(ops); {
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+
+		// Extend the hunk while the gaps between changes are small enough to share context
+		end := i + 1
+		for j := end; j < len(ops); {
+			if ops[j].kind != ' ' {
+				j++
+				end = j
+				continue
+			}
+			k := j
+			for k < len(ops) && ops[k].kind == ' ' {
+				k++
+			}
+			if k == len(ops) || k-j > 2*diffContext {
+				break
 			}
-			if newLine != "" {
-				diff.WriteString(fmt.Sprintf("+%s\n", newLine))
+			j = k
+		}
+
+		start := max(0, i-diffContext)
+		stop := min(len(ops), end+diffContext)
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n",
+			hunkRange(oldPos[start], oldPos[stop]-oldPos[start]),
+			hunkRange(newPos[start], newPos[stop]-newPos[start]))
+		for _, op := range ops[start:stop] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			if !strings.HasSuffix(op.line, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
 			}
-		} else if oldLine != "" {
-			diff.WriteString(fmt.Sprintf(" %s\n", oldLine))
 		}
+		i = stop
 	}
+	return b.String()
+}
 
-	return diff.String()
+// hunkRange formats the start,count of a hunk side; an empty side names the line before it
+func hunkRange(before int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", before+1)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// splitLines splits text into lines that keep their "\n", so a missing final
+// newline counts as a difference
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines matches the lines of a and b by longest common subsequence
+func diffLines(a []string, b []string) []diffOp {
+	var ops []diffOp
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		ops = append(ops, diffOp{' ', a[prefix]})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	n, m := len(midA), len(midB)
+	if n*m > maxDiffCells {
+		for _, line := range midA {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		// lcs[i*(m+1)+j] is the LCS length of midA[i:] and midB[j:]
+		lcs := make([]int32, (n+1)*(m+1))
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+				} else {
+					lcs[i*(m+1)+j] = max(lcs[(i+1)*(m+1)+j], lcs[i*(m+1)+j+1])
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n || j < m {
+			switch {
+			case i < n && j < m && midA[i] == midB[j]:
+				ops = append(ops, diffOp{' ', midA[i]})
+				i++
+				j++
+			case i < n && (j == m || lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]):
+				ops = append(ops, diffOp{'-', midA[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', midB[j]})
+				j++
+			}
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// isBinary reports whether content looks like binary data rather than text
+func isBinary(content []byte) bool {
+	return strings.IndexByte(string(content[:min(len(content), 8000)]), 0) >= 0
 }
 
 // PrintColoredDiff prints a diff with color coding
 func PrintColoredDiff(diff string) {
-	lines := strings.Split(diff, "\n")
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
 	for _, line := range lines {
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "diff --git"):
+			color.New(color.Bold).Println(line)
+		case strings.HasPrefix(line, "+"):
 			color.Green(line)
-		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
+		case strings.HasPrefix(line, "-"):
 			color.Red(line)
-		} else if strings.HasPrefix(line, " ") {
-			fmt.Println(line)
-		} else {
+		case strings.HasPrefix(line, "@@"):
+			color.Cyan(line)
+		default:
 			fmt.Println(line)
 		}
 	}
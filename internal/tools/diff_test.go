@@ -0,0 +1,66 @@
+package tools
+
+import "testing"
+
+func TestFormatPatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		change FileChange
+		want   string
+	}{
+		{
+			name:   "add",
+			change: FileChange{Path: "new.txt", New: []byte("one\ntwo\n"), NewExists: true},
+			want: "diff --git a/new.txt b/new.txt\nnew file mode 100644\n--- /dev/null\n+++ b/new.txt\n" +
+				"@@ -0,0 +1,2 @@\n+one\n+two\n",
+		},
+		{
+			name:   "delete",
+			change: FileChange{Path: "old.txt", Old: []byte("gone\n"), OldExists: true},
+			want: "diff --git a/old.txt b/old.txt\ndeleted file mode 100644\n--- a/old.txt\n+++ /dev/null\n" +
+				"@@ -1 +0,0 @@\n-gone\n",
+		},
+		{
+			name:   "modify",
+			change: FileChange{Path: "f.txt", Old: []byte("a\nb\nc\n"), New: []byte("a\nB\nc\n"), OldExists: true, NewExists: true},
+			want:   "diff --git a/f.txt b/f.txt\n--- a/f.txt\n+++ b/f.txt\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name:   "no final newline",
+			change: FileChange{Path: "f.txt", Old: []byte("a\n"), New: []byte("a\nb"), OldExists: true, NewExists: true},
+			want:   "diff --git a/f.txt b/f.txt\n--- a/f.txt\n+++ b/f.txt\n@@ -1 +1,2 @@\n a\n+b\n\\ No newline at end of file\n",
+		},
+		{
+			name:   "crlf line kept",
+			change: FileChange{Path: "w.txt", Old: []byte("a\r\nb\r\n"), New: []byte("a\r\nc\r\n"), OldExists: true, NewExists: true},
+			want:   "diff --git a/w.txt b/w.txt\n--- a/w.txt\n+++ b/w.txt\n@@ -1,2 +1,2 @@\n a\r\n-b\r\n+c\r\n",
+		},
+		{
+			name:   "crlf to lf",
+			change: FileChange{Path: "w.txt", Old: []byte("a\r\nb\r\n"), New: []byte("a\nb\n"), OldExists: true, NewExists: true},
+			want:   "diff --git a/w.txt b/w.txt\n--- a/w.txt\n+++ b/w.txt\n@@ -1,2 +1,2 @@\n-a\r\n-b\r\n+a\n+b\n",
+		},
+		{
+			name:   "binary",
+			change: FileChange{Path: "img.bin", New: []byte{0x89, 0, 1}, NewExists: true},
+			want:   "diff --git a/img.bin b/img.bin\nnew file mode 100644\nBinary files /dev/null and b/img.bin differ\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatPatch([]FileChange{tt.change}); got != tt.want {
+				t.Errorf("FormatPatch =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffHunksSplitsDistantChanges(t *testing.T) {
+	old := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	newText := "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\nY\n12\n"
+	want := "@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n" +
+		"@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-11\n+Y\n 12\n"
+	if got := diffHunks(old, newText); got != want {
+		t.Errorf("diffHunks =\n%s\nwant\n%s", got, want)
+	}
+}
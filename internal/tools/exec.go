@@ -1,16 +1,17 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os/exec"
 	"path/filepath"
 	"time"
 )
 
-var allowedExtensions = []string{".go", ".py", ".sh", ".js", ".ts"}
-
-// RunFile executes a file
-func (tm *ToolManager) RunFile(filePath string, args []string) (string, error) {
+// RunFile executes a file. The process is killed when ctx is cancelled or the timeout elapses.
+func (tm *ToolManager) RunFile(ctx context.Context, filePath string, args []string) (string, error) {
 	absPath, err := tm.validatePath(filePath)
 	if err != nil {
 		return "", err
@@ -19,52 +20,52 @@ func (tm *ToolManager) RunFile(filePath string, args []string) (string, error) {
 	// Check file extension
 	ext := filepath.Ext(absPath)
 	allowed := false
-	for _, allowedExt := range allowedExtensions {
+	for _, allowedExt := range tm.policy.RunExtensions {
 		if ext == allowedExt {
 			allowed = true
 			break
 		}
 	}
 	if !allowed {
-		return "", fmt.Errorf("file type not allowed: %s (allowed: %v)", ext, allowedExtensions)
+		return "", fmt.Errorf("file type not allowed: %s (allowed: %v)", ext, tm.policy.RunExtensions)
 	}
 
+	runCtx, cancel := context.WithTimeout(ctx, tm.policy.ExecTimeout)
+	defer cancel()
+
 	// Determine command based on extension
 	var cmd *exec.Cmd
 	switch ext {
 	case ".go":
-		cmd = exec.Command("go", append([]string{"run", absPath}, args...)...)
+		cmd = exec.CommandContext(runCtx, "go", append([]string{"run", absPath}, args...)...)
 	case ".py":
-		cmd = exec.Command("python3", append([]string{absPath}, args...)...)
+		cmd = exec.CommandContext(runCtx, "python3", append([]string{absPath}, args...)...)
 	case ".sh":
-		cmd = exec.Command("bash", append([]string{absPath}, args...)...)
+		cmd = exec.CommandContext(runCtx, "bash", append([]string{absPath}, args...)...)
 	case ".js":
-		cmd = exec.Command("node", append([]string{absPath}, args...)...)
+		cmd = exec.CommandContext(runCtx, "node", append([]string{absPath}, args...)...)
 	case ".ts":
-		cmd = exec.Command("ts-node", append([]string{absPath}, args...)...)
+		cmd = exec.CommandContext(runCtx, "ts-node", append([]string{absPath}, args...)...)
+	default:
+		return "", fmt.Errorf("no runner known for %s files", ext)
 	}
 
 	// Set working directory
 	cmd.Dir = tm.workDir
+	// Don't wait forever on pipes held open by grandchildren after a kill
+	cmd.WaitDelay = 2 * time.Second
 
-	// Capture output with timeout
-	done := make(chan error, 1)
-	output := make(chan string, 1)
-
-	go func() {
-		stdOut, _ := cmd.Output()
-		output <- string(stdOut)
-		done <- cmd.Run()
-	}()
-
-	select {
-	case <-time.After(30 * time.Second):
-		cmd.Process.Kill()
-		return "", fmt.Errorf("execution timeout (30 seconds)")
-	case err := <-done:
-		if err != nil {
-			return "", fmt.Errorf("execution failed: %w", err)
+	start := time.Now()
+	output, err := cmd.Output()
+	slog.Debug("ran file", "path", filePath, "command", cmd.Args, "exit_code", cmd.ProcessState.ExitCode(), "duration", time.Since(start), "output_bytes", len(output))
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("execution timeout (%s)", tm.policy.ExecTimeout)
 		}
-		return <-output, nil
+		return "", fmt.Errorf("execution failed: %w", err)
 	}
+	return string(output), nil
 }
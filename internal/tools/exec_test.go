@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newScriptWorkDir returns a tool manager over a working directory holding a bash script
+func newScriptWorkDir(t *testing.T, script string) *ToolManager {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not available")
+	}
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "run.sh"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return NewToolManager(workDir, false)
+}
+
+func TestRunFile(t *testing.T) {
+	tm := newScriptWorkDir(t, "echo \"hello $1\"\n")
+	out, err := tm.RunFile(context.Background(), "run.sh", []string{"nova"})
+	if err != nil || out != "hello nova\n" {
+		t.Errorf("RunFile = %q, %v", out, err)
+	}
+	if _, err := tm.RunFile(context.Background(), "notes.txt", nil); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("RunFile on .txt error = %v", err)
+	}
+}
+
+func TestRunFileStopsWhenCanceled(t *testing.T) {
+	tm := newScriptWorkDir(t, "exec sleep 30\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := tm.RunFile(ctx, "run.sh", nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunFile error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunFile took %s after cancellation", elapsed)
+	}
+}
+
+func TestRunFileTimeout(t *testing.T) {
+	tm := newScriptWorkDir(t, "exec sleep 30\n")
+	policy := DefaultPolicy()
+	policy.ExecTimeout = 100 * time.Millisecond
+	tm.SetPolicy(policy)
+
+	if _, err := tm.RunFile(context.Background(), "run.sh", nil); err == nil || !strings.Contains(err.Error(), "execution timeout") {
+		t.Errorf("RunFile error = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,142 @@
+package tools
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// DeleteFile removes a single file. Directories are never deleted.
+func (tm *ToolManager) DeleteFile(filePath string) (string, error) {
+	absPath, err := tm.checkExistingFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if err := tm.CheckWrite(filePath); err != nil {
+		return "", err
+	}
+
+	if tm.overlay != nil {
+		_, err := os.Lstat(absPath)
+		tm.overlay.remove(tm.mustKey(filePath), err == nil)
+		tm.markWritten(filePath)
+		return fmt.Sprintf("[DRY RUN] File %s deleted (simulated)", filePath), nil
+	}
+
+	slog.Debug("deleting file", "path", filePath)
+	if err := os.Remove(absPath); err != nil {
+		return "", fmt.Errorf("failed to delete file: %w", err)
+	}
+	tm.markWritten(filePath)
+	return fmt.Sprintf("File %s deleted", filePath), nil
+}
+
+// MoveFile renames a file within the working directory. The destination must not exist.
+func (tm *ToolManager) MoveFile(sourcePath string, destPath string) (string, error) {
+	absSource, err := tm.checkExistingFile(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	absDest, err := tm.validatePath(destPath)
+	if err != nil {
+		return "", err
+	}
+	if err := tm.CheckWrite(sourcePath); err != nil {
+		return "", err
+	}
+	if err := tm.CheckWrite(destPath); err != nil {
+		return "", err
+	}
+	if tm.overlay != nil {
+		return tm.simulateMove(sourcePath, absSource, destPath, absDest)
+	}
+	if _, err := os.Lstat(absDest); err == nil {
+		return "", fmt.Errorf("destination %s already exists", destPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(absDest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directories: %w", err)
+	}
+	slog.Debug("moving file", "from", sourcePath, "to", destPath)
+	if err := os.Rename(absSource, absDest); err != nil {
+		return "", fmt.Errorf("failed to move file: %w", err)
+	}
+	tm.markWritten(sourcePath)
+	tm.markWritten(destPath)
+	return fmt.Sprintf("File %s moved to %s", sourcePath, destPath), nil
+}
+
+// CreateDirectory creates a directory and any missing parents
+func (tm *ToolManager) CreateDirectory(dirPath string) (string, error) {
+	absPath, err := tm.validatePath(dirPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := tm.overlayKey(dirPath)
+	if err != nil {
+		return "", err
+	}
+	if exists, isDir := tm.statFile(rel, absPath); exists {
+		if isDir {
+			return fmt.Sprintf("Directory %s already exists", dirPath), nil
+		}
+		return "", fmt.Errorf("%s already exists and is not a directory", dirPath)
+	}
+	if err := tm.CheckCreateDir(dirPath); err != nil {
+		return "", err
+	}
+
+	if tm.overlay != nil {
+		tm.overlay.mkdir(rel)
+		return fmt.Sprintf("[DRY RUN] Directory %s created (simulated)", dirPath), nil
+	}
+
+	slog.Debug("creating directory", "path", dirPath)
+	if err := os.MkdirAll(absPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	return fmt.Sprintf("Directory %s created", dirPath), nil
+}
+
+// DescribeFile summarizes an existing file for confirmations and dry-run previews
+func (tm *ToolManager) DescribeFile(filePath string) (string, error) {
+	absPath, err := tm.checkExistingFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if f, ok := tm.overlay.lookup(tm.mustKey(filePath)); ok {
+		return fmt.Sprintf("%s (%d bytes, changed in this dry run)", filePath, len(f.content)), nil
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s (%d bytes, modified %s)", filePath, info.Size(), info.ModTime().Format("2006-01-02 15:04")), nil
+}
+
+// checkExistingFile validates filePath and requires it to be a regular file
+func (tm *ToolManager) checkExistingFile(filePath string) (string, error) {
+	absPath, err := tm.validatePath(filePath)
+	if err != nil {
+		return "", err
+	}
+	if tm.overlay != nil {
+		exists, isDir := tm.statFile(tm.mustKey(filePath), absPath)
+		if !exists {
+			return "", fmt.Errorf("file not found: %s", filePath)
+		}
+		if isDir {
+			return "", fmt.Errorf("%s is a directory; only files can be deleted or moved", filePath)
+		}
+		return absPath, nil
+	}
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("file not found: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory; only files can be deleted or moved", filePath)
+	}
+	return absPath, nil
+}
@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newFileOpsWorkDir returns a tool manager over a working directory holding files
+func newFileOpsWorkDir(t *testing.T, files ...string) (*ToolManager, string) {
+	t.Helper()
+	workDir := t.TempDir()
+	for _, name := range files {
+		path := filepath.Join(workDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewToolManager(workDir, false), workDir
+}
+
+func TestDeleteFile(t *testing.T) {
+	tm, workDir := newFileOpsWorkDir(t, "old.txt", "src/main.go", "go.sum")
+
+	if _, err := tm.DeleteFile("old.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "old.txt")); !os.IsNotExist(err) {
+		t.Error("old.txt still exists")
+	}
+
+	for path, want := range map[string]string{
+		"src":         "is a directory",
+		"missing.txt": "file not found",
+		"go.sum":      "protected",
+		"../x.txt":    "outside",
+	} {
+		if _, err := tm.DeleteFile(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DeleteFile(%q) error = %v, want %q", path, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "go.sum")); err != nil {
+		t.Error("protected go.sum was deleted")
+	}
+}
+
+func TestMoveFile(t *testing.T) {
+	tm, workDir := newFileOpsWorkDir(t, "a.txt", "b.txt", "go.sum")
+
+	if _, err := tm.MoveFile("a.txt", "docs/a.txt"); err != nil {
+		t.Fatalf("MoveFile: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(workDir, "docs", "a.txt")); err != nil || string(data) != "a.txt" {
+		t.Errorf("moved file = %q, %v", data, err)
+	}
+
+	for _, tt := range []struct{ from, to, want string }{
+		{"b.txt", "docs/a.txt", "already exists"},
+		{"b.txt", "go.sum", "protected"},
+		{"go.sum", "deps.txt", "protected"},
+		{"b.txt", "tool.exe", "write policy"},
+		{"missing.txt", "c.txt", "file not found"},
+	} {
+		if _, err := tm.MoveFile(tt.from, tt.to); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("MoveFile(%q, %q) error = %v, want %q", tt.from, tt.to, err, tt.want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "b.txt")); err != nil {
+		t.Error("b.txt moved despite the errors")
+	}
+}
+
+func TestCreateDirectory(t *testing.T) {
+	tm, workDir := newFileOpsWorkDir(t, "notes.txt")
+
+	if _, err := tm.CreateDirectory("src/pkg"); err != nil {
+		t.Fatalf("CreateDirectory: %v", err)
+	}
+	if info, err := os.Stat(filepath.Join(workDir, "src", "pkg")); err != nil || !info.IsDir() {
+		t.Errorf("src/pkg not created: %v", err)
+	}
+	if out, err := tm.CreateDirectory("src"); err != nil || !strings.Contains(out, "already exists") {
+		t.Errorf("CreateDirectory on an existing directory = %q, %v", out, err)
+	}
+	if _, err := tm.CreateDirectory("notes.txt"); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("CreateDirectory over a file error = %v", err)
+	}
+	if _, err := tm.CreateDirectory(".git/hooks"); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Errorf("CreateDirectory in .git error = %v", err)
+	}
+
+	policy := DefaultPolicy()
+	policy.CreateDirs = false
+	tm.SetPolicy(policy)
+	if _, err := tm.CreateDirectory("build"); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("CreateDirectory with create_dirs off error = %v", err)
+	}
+}
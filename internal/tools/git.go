@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/git"
+)
+
+const (
+	// MaxGitOutput caps the output returned by the git tools, in bytes
+	MaxGitOutput = 20000
+
+	defaultGitLogCount = 10
+	maxGitLogCount     = 50
+)
+
+// GitStatus shows the branch and uncommitted changes in the working directory
+func (tm *ToolManager) GitStatus(ctx context.Context) (string, error) {
+	return tm.git(ctx, "status", "--short", "--branch", "--", ".")
+}
+
+// GitDiff shows uncommitted changes, or staged changes if staged is set. ref
+// (e.g. "HEAD~1" or "main..HEAD") compares against commits instead. path limits
+// the diff to one file or directory.
+func (tm *ToolManager) GitDiff(ctx context.Context, path string, ref string, staged bool) (string, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--no-textconv"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if ref != "" {
+		if err := validateRef(ref); err != nil {
+			return "", err
+		}
+		args = append(args, ref)
+	}
+
+	spec, err := tm.gitPathspec(path)
+	if err != nil {
+		return "", err
+	}
+	out, err := tm.git(ctx, append(args, spec...)...)
+	if err == nil && out == "" {
+		return "No differences.", nil
+	}
+	return out, err
+}
+
+// GitLog lists recent commits, optionally only those touching path
+func (tm *ToolManager) GitLog(ctx context.Context, path string, count int) (string, error) {
+	if count <= 0 {
+		count = defaultGitLogCount
+	}
+	if count > maxGitLogCount {
+		count = maxGitLogCount
+	}
+
+	spec, err := tm.gitPathspec(path)
+	if err != nil {
+		return "", err
+	}
+	args := []string{"log", "--no-color", fmt.Sprintf("--max-count=%d", count), "--date=short", "--format=%h %ad %an%n    %s", "--stat"}
+	return tm.git(ctx, append(args, spec...)...)
+}
+
+// GitBlame shows who last changed each line of a file, optionally limited to a line range
+func (tm *ToolManager) GitBlame(ctx context.Context, filePath string, startLine int, endLine int) (string, error) {
+	if filePath == "" {
+		return "", fmt.Errorf("missing file_path argument")
+	}
+	spec, err := tm.gitPathspec(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"blame", "--date=short"}
+	if startLine > 0 {
+		if endLine < startLine {
+			endLine = startLine
+		}
+		args = append(args, fmt.Sprintf("-L%d,%d", startLine, endLine))
+	}
+	return tm.git(ctx, append(args, spec...)...)
+}
+
+// git runs a read-only git command in the working directory with the exec timeout,
+// capping its output
+func (tm *ToolManager) git(ctx context.Context, args ...string) (string, error) {
+	runCtx, cancel := context.WithTimeout(ctx, tm.policy.ExecTimeout)
+	defer cancel()
+
+	repo, err := git.Open(runCtx, tm.workDir)
+	if err != nil {
+		return "", err
+	}
+	if repo == nil {
+		return "", fmt.Errorf("the working directory is not in a git repository")
+	}
+
+	out, err := repo.Output(runCtx, args...)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+	if len(out) > MaxGitOutput {
+		out = CutAtRune(out, MaxGitOutput) + fmt.Sprintf("\n... [output truncated at %d bytes; narrow the request with a path or range]", MaxGitOutput)
+	}
+	return out, nil
+}
+
+// gitPathspec validates an optional path and returns the arguments selecting it
+func (tm *ToolManager) gitPathspec(path string) ([]string, error) {
+	if path == "" {
+		return []string{"--", "."}, nil
+	}
+	rel, err := tm.RelPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if tm.IsIgnored(filepath.ToSlash(rel)) {
+		return nil, fmt.Errorf("%s is ignored", path)
+	}
+	return []string{"--", rel}, nil
+}
+
+// validateRef rejects revisions that git could parse as options
+func validateRef(ref string) error {
+	if strings.HasPrefix(ref, "-") || strings.ContainsAny(ref, " \t\n") {
+		return fmt.Errorf("invalid git revision %q", ref)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// newGitWorkDir creates a repository whose first commit holds the given files
+func newGitWorkDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "-A"}, {"commit", "-q", "-m", "initial import"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestGitTools(t *testing.T) {
+	dir := newGitWorkDir(t, map[string]string{"main.go": "package main\n", "docs/a.md": "a\n"})
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	tm := NewToolManager(dir, false)
+	ctx := context.Background()
+
+	status, err := tm.GitStatus(ctx)
+	if err != nil || !strings.Contains(status, " M main.go") {
+		t.Errorf("GitStatus = %q (%v), want main.go modified", status, err)
+	}
+
+	diff, err := tm.GitDiff(ctx, "main.go", "", false)
+	if err != nil || !strings.Contains(diff, "+func main() {}") {
+		t.Errorf("GitDiff = %q (%v), want the added line", diff, err)
+	}
+	if diff, err := tm.GitDiff(ctx, "docs", "", false); err != nil || diff != "No differences." {
+		t.Errorf("GitDiff(docs) = %q (%v), want no differences", diff, err)
+	}
+
+	log, err := tm.GitLog(ctx, "", 0)
+	if err != nil || !strings.Contains(log, "initial import") {
+		t.Errorf("GitLog = %q (%v), want the commit", log, err)
+	}
+
+	blame, err := tm.GitBlame(ctx, "docs/a.md", 1, 1)
+	if err != nil || !strings.Contains(blame, "test") {
+		t.Errorf("GitBlame = %q (%v), want the author", blame, err)
+	}
+}
+
+func TestGitToolsRejectUnsafeArguments(t *testing.T) {
+	dir := newGitWorkDir(t, map[string]string{"a.txt": "a\n"})
+	tm := NewToolManager(dir, false)
+	ctx := context.Background()
+
+	if _, err := tm.GitDiff(ctx, "", "--output=/tmp/x", false); err == nil {
+		t.Error("GitDiff accepted an option as the revision")
+	}
+	if _, err := tm.GitLog(ctx, "../outside", 1); err == nil {
+		t.Error("GitLog accepted a path outside the working directory")
+	}
+	if _, err := tm.GitBlame(ctx, "", 0, 0); err == nil {
+		t.Error("GitBlame accepted an empty file path")
+	}
+}
+
+func TestGitOutputTruncatedOnRuneBoundary(t *testing.T) {
+	// Three-byte runes, offset so one straddles the cap
+	content := "x" + strings.Repeat("日本語\n", MaxGitOutput/5)
+	dir := newGitWorkDir(t, map[string]string{"text.txt": content})
+	tm := NewToolManager(dir, false)
+
+	out, err := tm.GitBlame(context.Background(), "text.txt", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "output truncated at") {
+		t.Fatalf("output of %d bytes was not truncated", len(out))
+	}
+	if !utf8.ValidString(out) {
+		t.Error("truncated output is not valid UTF-8")
+	}
+}
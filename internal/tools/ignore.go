@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the per-project file listing extra paths the agent should not pull into context
+const IgnoreFileName = ".novaignore"
+
+// defaultIgnored are directory or file names that are always skipped
+var defaultIgnored = []string{".git", "node_modules", ".venv", "__pycache__", ".DS_Store"}
+
+// IsIgnored reports whether a path relative to the working directory matches the default
+// ignore list or a pattern from .novaignore. Patterns are matched against every path
+// component as well as the full relative path.
+func (tm *ToolManager) IsIgnored(relPath string) bool {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+	components := strings.Split(relPath, "/")
+
+	patterns := append(append([]string{}, defaultIgnored...), tm.loadIgnorePatterns()...)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(pattern, "/")
+		if ok, _ := filepath.Match(pattern, relPath); ok {
+			return true
+		}
+		for _, c := range components {
+			if ok, _ := filepath.Match(pattern, c); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (tm *ToolManager) loadIgnorePatterns() []string {
+	f, err := os.Open(filepath.Join(tm.workDir, IgnoreFileName))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
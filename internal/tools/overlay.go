@@ -0,0 +1,299 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Overlay records file changes in memory instead of on disk, for dry runs.
+// Reads through the ToolManager see the simulated state; the disk is never touched.
+type Overlay struct {
+	files map[string]*overlayFile // Keyed by workdir-relative, slash-separated path
+	dirs  map[string]bool         // Directories created by the simulation
+}
+
+type overlayFile struct {
+	content []byte
+	deleted bool
+}
+
+// FileChange is a file whose simulated content differs from the disk
+type FileChange struct {
+	Path      string
+	Old       []byte
+	New       []byte
+	OldExists bool
+	NewExists bool
+}
+
+// EnableOverlay makes every change through this ToolManager simulated in memory
+func (tm *ToolManager) EnableOverlay() {
+	tm.overlay = &Overlay{
+		files: make(map[string]*overlayFile),
+		dirs:  make(map[string]bool),
+	}
+}
+
+// Simulated reports whether changes go to an overlay instead of the disk
+func (tm *ToolManager) Simulated() bool {
+	return tm.overlay != nil
+}
+
+// Changes compares the overlay with the disk and returns the files that would change,
+// sorted by path, and the new directories that hold no changed files
+func (tm *ToolManager) Changes() ([]FileChange, []string) {
+	if tm.overlay == nil {
+		return nil, nil
+	}
+
+	var changes []FileChange
+	for rel, f := range tm.overlay.files {
+		old, err := os.ReadFile(filepath.Join(tm.workDir, filepath.FromSlash(rel)))
+		c := FileChange{Path: rel, Old: old, OldExists: err == nil, New: f.content, NewExists: !f.deleted}
+		if c.OldExists == c.NewExists && (!c.NewExists || bytes.Equal(c.Old, c.New)) {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+
+	// Parents of other new directories are implied, so only the innermost are listed
+	var dirs []string
+	for dir := range tm.overlay.dirs {
+		if !tm.overlay.hasFilesUnder(dir) && !tm.overlay.hasDirsUnder(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return changes, dirs
+}
+
+// overlayKey returns the overlay key for a path inside the working directory
+func (tm *ToolManager) overlayKey(filePath string) (string, error) {
+	rel, err := tm.RelPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// lookup returns the simulated state of a file; ok is false if the overlay has no entry
+func (o *Overlay) lookup(rel string) (f *overlayFile, ok bool) {
+	if o == nil {
+		return nil, false
+	}
+	f, ok = o.files[rel]
+	return f, ok
+}
+
+// isDir reports whether rel is a directory created by the simulation,
+// or holds a simulated file
+func (o *Overlay) isDir(rel string) bool {
+	if o == nil {
+		return false
+	}
+	return o.dirs[rel] || o.hasFilesUnder(rel)
+}
+
+func (o *Overlay) hasFilesUnder(dir string) bool {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+	for rel, f := range o.files {
+		if !f.deleted && strings.HasPrefix(rel, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *Overlay) hasDirsUnder(dir string) bool {
+	for rel := range o.dirs {
+		if strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// entries returns the simulated direct children of dir: name to overlay file,
+// with nil for directories
+func (o *Overlay) entries(dir string) map[string]*overlayFile {
+	children := make(map[string]*overlayFile)
+	if o == nil {
+		return children
+	}
+	child := func(rel string) (string, bool) {
+		if dir != "." {
+			if !strings.HasPrefix(rel, dir+"/") {
+				return "", false
+			}
+			rel = rel[len(dir)+1:]
+		}
+		return rel, rel != ""
+	}
+
+	for rel, f := range o.files {
+		if rest, ok := child(rel); ok {
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				if !f.deleted {
+					children[rest[:i]] = nil
+				}
+			} else {
+				children[rest] = f
+			}
+		}
+	}
+	for rel := range o.dirs {
+		if rest, ok := child(rel); ok {
+			children[strings.SplitN(rest, "/", 2)[0]] = nil
+		}
+	}
+	return children
+}
+
+func (o *Overlay) write(rel string, content []byte) {
+	o.files[rel] = &overlayFile{content: content}
+}
+
+// remove deletes a file from the simulation; files that exist on disk are marked deleted
+func (o *Overlay) remove(rel string, onDisk bool) {
+	if onDisk {
+		o.files[rel] = &overlayFile{deleted: true}
+		return
+	}
+	delete(o.files, rel)
+}
+
+func (o *Overlay) mkdir(rel string) {
+	for dir := rel; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		o.dirs[dir] = true
+	}
+}
+
+// readFile returns a file's content as the tools currently see it
+func (tm *ToolManager) readFile(rel string, absPath string) ([]byte, error) {
+	if f, ok := tm.overlay.lookup(rel); ok {
+		if f.deleted {
+			return nil, fmt.Errorf("open %s: %w", rel, os.ErrNotExist)
+		}
+		return f.content, nil
+	}
+	return os.ReadFile(absPath)
+}
+
+// statFile reports whether a path exists as the tools currently see it, and whether it is a directory
+func (tm *ToolManager) statFile(rel string, absPath string) (exists bool, isDir bool) {
+	if f, ok := tm.overlay.lookup(rel); ok {
+		return !f.deleted, false
+	}
+	if tm.overlay.isDir(rel) {
+		return true, true
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return false, false
+	}
+	return true, info.IsDir()
+}
+
+// mustKey returns the overlay key for a path that has already been validated
+func (tm *ToolManager) mustKey(filePath string) string {
+	rel, _ := tm.overlayKey(filePath)
+	return rel
+}
+
+// simulatedFilesInfo lists a directory with the overlay's changes applied
+func (tm *ToolManager) simulatedFilesInfo(directory string, absPath string) (string, error) {
+	rel := tm.mustKey(directory)
+
+	type entry struct {
+		size  int64
+		isDir bool
+	}
+	listing := make(map[string]entry)
+
+	dirEntries, err := os.ReadDir(absPath)
+	if err != nil && !tm.overlay.isDir(rel) {
+		return "", fmt.Errorf("failed to read directory: %w", err)
+	}
+	for _, e := range dirEntries {
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		listing[e.Name()] = entry{size: info.Size(), isDir: e.IsDir()}
+	}
+
+	for name, f := range tm.overlay.entries(rel) {
+		switch {
+		case f == nil:
+			if _, ok := listing[name]; !ok {
+				listing[name] = entry{isDir: true}
+			}
+		case f.deleted:
+			delete(listing, name)
+		default:
+			listing[name] = entry{size: int64(len(f.content))}
+		}
+	}
+
+	names := make([]string, 0, len(listing))
+	for name := range listing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	for _, name := range names {
+		e := listing[name]
+		result.WriteString(fmt.Sprintf("- %s: file_size=%d bytes, is_dir=%v\n", name, e.size, e.isDir))
+	}
+	return result.String(), nil
+}
+
+// simulatedFileContent reads a file with the overlay's changes applied
+func (tm *ToolManager) simulatedFileContent(filePath string, absPath string) (string, error) {
+	rel := tm.mustKey(filePath)
+	exists, isDir := tm.statFile(rel, absPath)
+	if !exists {
+		return "", fmt.Errorf("file not found: %s", filePath)
+	}
+	if isDir {
+		return "", fmt.Errorf("cannot read directory as file")
+	}
+
+	content, err := tm.readFile(rel, absPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	if int64(len(content)) > tm.policy.MaxFileSize {
+		return "", fmt.Errorf("file too large (%d bytes, max %d)", len(content), tm.policy.MaxFileSize)
+	}
+	return string(content), nil
+}
+
+// simulateMove moves a file within the overlay
+func (tm *ToolManager) simulateMove(sourcePath string, absSource string, destPath string, absDest string) (string, error) {
+	source, dest := tm.mustKey(sourcePath), tm.mustKey(destPath)
+	if exists, _ := tm.statFile(dest, absDest); exists {
+		return "", fmt.Errorf("destination %s already exists", destPath)
+	}
+
+	content, err := tm.readFile(source, absSource)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+	_, err = os.Lstat(absSource)
+	tm.overlay.write(dest, content)
+	tm.overlay.remove(source, err == nil)
+	tm.markWritten(sourcePath)
+	tm.markWritten(destPath)
+	return fmt.Sprintf("[DRY RUN] File %s moved to %s (simulated)", sourcePath, destPath), nil
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverlayLeavesDiskUntouched(t *testing.T) {
+	workDir := t.TempDir()
+	for name, content := range map[string]string{"keep.txt": "keep\n", "edit.txt": "old\n", "gone.txt": "bye\n", "move.txt": "moving\n"} {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tm := NewToolManager(workDir, false)
+	tm.EnableOverlay()
+	steps := []func() (string, error){
+		func() (string, error) { return tm.WriteFile("edit.txt", "new\n") },
+		func() (string, error) { return tm.WriteFile("pkg/new.go", "package pkg\n") },
+		func() (string, error) { return tm.DeleteFile("gone.txt") },
+		func() (string, error) { return tm.MoveFile("move.txt", "moved/move.txt") },
+		func() (string, error) { return tm.CreateDirectory("empty/inner") },
+	}
+	for i, step := range steps {
+		if _, err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	// Later reads see the simulation
+	if got, err := tm.GetFileContent("edit.txt"); err != nil || got != "new\n" {
+		t.Errorf("edit.txt reads %q (%v), want the simulated content", got, err)
+	}
+	if _, err := tm.GetFileContent("gone.txt"); err == nil {
+		t.Error("deleted gone.txt can still be read")
+	}
+	listing, err := tm.GetFilesInfo(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"- pkg:", "- moved:", "- empty:", "- keep.txt:"} {
+		if !strings.Contains(listing, name) {
+			t.Errorf("listing is missing %q:\n%s", name, listing)
+		}
+	}
+	for _, name := range []string{"gone.txt", "move.txt:"} {
+		if strings.Contains(listing, "- "+name) {
+			t.Errorf("listing still shows %q:\n%s", name, listing)
+		}
+	}
+
+	changes, dirs := tm.Changes()
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Path)
+	}
+	if want := "edit.txt gone.txt move.txt moved/move.txt pkg/new.go"; strings.Join(got, " ") != want {
+		t.Errorf("changes = %v, want %s", got, want)
+	}
+	if strings.Join(dirs, " ") != "empty/inner" {
+		t.Errorf("new directories = %v, want only empty/inner", dirs)
+	}
+
+	// Nothing reached the disk
+	entries, _ := os.ReadDir(workDir)
+	if len(entries) != 4 {
+		t.Errorf("working directory has %d entries, want the original 4", len(entries))
+	}
+	if data, _ := os.ReadFile(filepath.Join(workDir, "edit.txt")); string(data) != "old\n" {
+		t.Errorf("edit.txt on disk = %q, want it unchanged", data)
+	}
+}
+
+func TestOverlayIgnoresRevertedChanges(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "a.txt"), []byte("a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := NewToolManager(workDir, false)
+	tm.EnableOverlay()
+	if _, err := tm.WriteFile("a.txt", "b\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.WriteFile("a.txt", "a\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.WriteFile("tmp.txt", "x\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.DeleteFile("tmp.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if changes, _ := tm.Changes(); len(changes) != 0 {
+		t.Errorf("changes = %+v, want none", changes)
+	}
+}
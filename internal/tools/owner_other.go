@@ -0,0 +1,13 @@
+//go:build !unix
+
+package tools
+
+import "os"
+
+// copyOwner is a no-op where files have no unix owner
+func copyOwner(info os.FileInfo, path string) error {
+	return nil
+}
+
+// syncDir is a no-op where directories cannot be synced
+func syncDir(dir string) {}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package tools
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyOwner gives path the owner and group recorded in info. Nothing is changed
+// when they already match the current user.
+func copyOwner(info os.FileInfo, path string) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	uid, gid := int(stat.Uid), int(stat.Gid)
+	if uid == os.Getuid() && gid == os.Getgid() {
+		return nil
+	}
+	return os.Lchown(path, uid, gid)
+}
+
+// syncDir flushes a directory entry change (such as a rename) to disk
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	MaxPinnedSize = 250000 // 250KB across all pinned files
+)
+
+// PinnedFile is a file matched by a pin pattern
+type PinnedFile struct {
+	Path string // Relative to the working directory
+	Size int64
+}
+
+// ResolvePins expands glob patterns (relative to the working directory) into the files
+// they currently match, skipping directories, ignored paths and files over the size limit.
+func (tm *ToolManager) ResolvePins(patterns []string) ([]PinnedFile, error) {
+	seen := make(map[string]bool)
+	var files []PinnedFile
+
+	for _, pattern := range patterns {
+		if _, err := tm.validatePath(pattern); err != nil {
+			return nil, err
+		}
+
+		matches, err := filepath.Glob(filepath.Join(tm.workDir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			rel, err := filepath.Rel(tm.workDir, match)
+			if err != nil || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if seen[rel] || tm.IsIgnored(rel) {
+				continue
+			}
+
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() || info.Size() > tm.policy.MaxFileSize {
+				continue
+			}
+
+			seen[rel] = true
+			files = append(files, PinnedFile{Path: rel, Size: info.Size()})
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// PinnedSize returns the total size of the given files
+func PinnedSize(files []PinnedFile) int64 {
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	return total
+}
+
+// RenderPins reads the current content of the pinned files into a single block of text.
+// Files that no longer fit within MaxPinnedSize are reported in skipped.
+func (tm *ToolManager) RenderPins(files []PinnedFile) (text string, skipped []string) {
+	var b strings.Builder
+	var total int64
+
+	for _, f := range files {
+		content, err := tm.GetFileContent(f.Path)
+		if err != nil || total+int64(len(content)) > MaxPinnedSize {
+			skipped = append(skipped, f.Path)
+			continue
+		}
+		total += int64(len(content))
+		fmt.Fprintf(&b, "--- %s ---\n%s\n", f.Path, content)
+	}
+
+	return b.String(), skipped
+}
@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolvePins(t *testing.T) {
+	workDir := t.TempDir()
+	for name, content := range map[string]string{
+		"main.go":             "package main\n",
+		"util.go":             "package main\n",
+		"README.md":           "# readme\n",
+		"vendor/skip.go":      "package skip\n",
+		"node_modules/dep.js": "module.exports = {}\n",
+	} {
+		path := filepath.Join(workDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	os.WriteFile(filepath.Join(workDir, IgnoreFileName), []byte("vendor/\n"), 0644)
+
+	tm := NewToolManager(workDir, false)
+	files, err := tm.ResolvePins([]string{"*.go", "main.go", "vendor/*.go", "node_modules/*", "vendor"})
+	if err != nil {
+		t.Fatalf("ResolvePins: %v", err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	if strings.Join(got, ",") != "main.go,util.go" {
+		t.Errorf("pinned %v, want [main.go util.go]", got)
+	}
+	if PinnedSize(files) != int64(2*len("package main\n")) {
+		t.Errorf("PinnedSize = %d", PinnedSize(files))
+	}
+
+	if _, err := tm.ResolvePins([]string{"../*"}); err == nil {
+		t.Error("pattern outside the working directory was accepted")
+	}
+}
+
+func TestRenderPinsSkipsFilesOverBudget(t *testing.T) {
+	workDir := t.TempDir()
+	big := strings.Repeat("x", MaxPinnedSize-10)
+	os.WriteFile(filepath.Join(workDir, "a.txt"), []byte(big), 0644)
+	os.WriteFile(filepath.Join(workDir, "b.txt"), []byte("more than ten bytes"), 0644)
+	os.WriteFile(filepath.Join(workDir, "c.txt"), []byte("short"), 0644)
+
+	tm := NewToolManager(workDir, false)
+	tm.policy.MaxFileSize = MaxPinnedSize
+	files, err := tm.ResolvePins([]string{"*.txt"})
+	if err != nil {
+		t.Fatalf("ResolvePins: %v", err)
+	}
+	text, skipped := tm.RenderPins(files)
+	if len(skipped) != 1 || skipped[0] != "b.txt" {
+		t.Errorf("skipped = %v, want [b.txt]", skipped)
+	}
+	if !strings.Contains(text, "--- a.txt ---\n") || !strings.Contains(text, "--- c.txt ---\nshort\n") {
+		t.Errorf("rendered pins missing files:\n%.200s", text)
+	}
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/brandnova/nova-horizon-cli/internal/audit"
+)
+
+// CheckWrite applies the write policy to filePath without writing anything.
+// Violations are reported as errors starting with "write policy:".
+func (tm *ToolManager) CheckWrite(filePath string) error {
+	absPath, err := tm.validatePath(filePath)
+	if err != nil {
+		return err
+	}
+	rel, err := tm.checkProtected(filePath)
+	if err != nil {
+		return err
+	}
+
+	if len(tm.policy.WriteExtensions) > 0 {
+		if err := ValidateFileExtension(rel, tm.policy.WriteExtensions); err != nil {
+			return fmt.Errorf("write policy: %w", err)
+		}
+	}
+
+	if max := tm.policy.MaxFilesWritten; max > 0 && !tm.written[rel] && len(tm.written) >= max {
+		return fmt.Errorf("write policy: this run has already written the maximum of %d files", max)
+	}
+
+	if !tm.policy.CreateDirs {
+		if exists, _ := tm.statFile(path.Dir(rel), filepath.Dir(absPath)); !exists {
+			return fmt.Errorf("write policy: creating new directories is not allowed (%s does not exist)", filepath.ToSlash(filepath.Dir(rel)))
+		}
+	}
+	return nil
+}
+
+// CheckCreateDir applies the write policy to creating the directory dirPath
+func (tm *ToolManager) CheckCreateDir(dirPath string) error {
+	rel, err := tm.checkProtected(dirPath)
+	if err != nil {
+		return err
+	}
+	if !tm.policy.CreateDirs {
+		return fmt.Errorf("write policy: creating new directories is not allowed (%s)", rel)
+	}
+	return nil
+}
+
+// checkProtected validates a path and rejects the audit log and protected globs.
+// It returns the path relative to the working directory, slash-separated.
+func (tm *ToolManager) checkProtected(filePath string) (string, error) {
+	rel, err := tm.RelPath(filePath)
+	if err != nil {
+		return "", err
+	}
+	rel = filepath.ToSlash(rel)
+
+	// The audit log is append-only for the agent's own actions, whatever the policy
+	if rel == filepath.ToSlash(audit.RelPath()) {
+		return "", fmt.Errorf("%s is the audit log and cannot be modified", filePath)
+	}
+
+	for _, pattern := range tm.policy.ProtectedPaths {
+		if MatchGlob(pattern, rel) {
+			return "", fmt.Errorf("write policy: %s is protected (matches %q)", rel, pattern)
+		}
+	}
+	return rel, nil
+}
+
+// MatchGlob reports whether a slash-separated relative path matches pattern.
+// A pattern without a slash matches the file name in any directory, "**" matches
+// any number of path segments, and other segments use filepath.Match syntax.
+func MatchGlob(pattern string, relPath string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	relPath = filepath.ToSlash(relPath)
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, relPath[strings.LastIndex(relPath, "/")+1:])
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(relPath, "/"))
+}
+
+func matchSegments(pattern []string, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// "**" absorbs zero or more segments
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
@@ -0,0 +1,43 @@
+package tools
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		// No slash: the file name in any directory
+		{"go.sum", "go.sum", true},
+		{"go.sum", "sub/mod/go.sum", true},
+		{"*.lock", "deps/yarn.lock", true},
+		{"*.lock", "lock/file.txt", false},
+
+		// "**" spans zero or more segments
+		{".git/**", ".git/config", true},
+		{".git/**", ".git/refs/heads/main", true},
+		{".git/**", ".git", true},
+		{".git/**", "src/.git/config", false},
+		{"**/node_modules/**", "node_modules/pkg/index.js", true},
+		{"**/node_modules/**", "web/app/node_modules/pkg/index.js", true},
+		{"**/node_modules/**", "web/node_modules_old/x.js", false},
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/c/main.go", true},
+		{"src/**/*.go", "src/a/b/c/main.py", false},
+		{"src/**/*.go", "lib/src/main.go", false},
+		{"**/*_test.go", "pkg/x_test.go", true},
+		{"**", "anything/at/all", true},
+
+		// Other segments match one path segment
+		{"deploy/*", "deploy/prod.yaml", true},
+		{"deploy/*", "deploy/prod/app.yaml", false},
+		{"./deploy/*.yaml", "deploy/prod.yaml", true},
+		{"a/b", "a/b/c", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
@@ -1,28 +1,73 @@
 package tools
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
-)
+	"time"
 
-const (
-	MaxFileSize = 100000 // 100KB
+	"github.com/brandnova/nova-horizon-cli/internal/defaults"
 )
 
+// Policy holds the configurable limits applied by the tools
+type Policy struct {
+	MaxFileSize   int64
+	ExecTimeout   time.Duration
+	RunExtensions []string
+
+	// Write policy (see policy.go). Empty WriteExtensions allows any extension;
+	// zero MaxFilesWritten allows any number of files.
+	WriteExtensions []string
+	ProtectedPaths  []string
+	MaxFilesWritten int
+	CreateDirs      bool
+}
+
+// DefaultPolicy returns the limits used when none are configured
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxFileSize:   defaults.MaxFileSize,
+		ExecTimeout:   defaults.ExecTimeout,
+		RunExtensions: append([]string(nil), defaults.RunExtensions...),
+
+		WriteExtensions: append([]string(nil), defaults.WriteExtensions...),
+		ProtectedPaths:  append([]string(nil), defaults.ProtectedPaths...),
+		MaxFilesWritten: defaults.MaxFilesWritten,
+		CreateDirs:      true,
+	}
+}
+
 type ToolManager struct {
 	workDir string
 	verbose bool
+	policy  Policy
+
+	// written holds the workdir-relative paths written so far, for Policy.MaxFilesWritten
+	written map[string]bool
+
+	// overlay holds simulated changes in dry runs (see overlay.go)
+	overlay *Overlay
 }
 
 func NewToolManager(workDir string, verbose bool) *ToolManager {
 	return &ToolManager{
 		workDir: workDir,
 		verbose: verbose,
+		policy:  DefaultPolicy(),
+		written: make(map[string]bool),
 	}
 }
 
+// SetPolicy replaces the limits applied by the tools
+func (tm *ToolManager) SetPolicy(p Policy) {
+	tm.policy = p
+}
+
 func (tm *ToolManager) validatePath(filePath string) (string, error) {
 	absWorkDir, err := filepath.Abs(tm.workDir)
 	if err != nil {
@@ -42,6 +87,36 @@ func (tm *ToolManager) validatePath(filePath string) (string, error) {
 	return absPath, nil
 }
 
+// workDirRel returns a symlink-resolved absolute path relative to the working directory;
+// ok is false if it lies outside
+func (tm *ToolManager) workDirRel(resolved string) (rel string, ok bool) {
+	root, err := filepath.Abs(tm.workDir)
+	if err != nil {
+		return "", false
+	}
+	if r, err := filepath.EvalSymlinks(root); err == nil {
+		root = r
+	}
+	rel, err = filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// RelPath validates filePath and returns it relative to the working directory
+func (tm *ToolManager) RelPath(filePath string) (string, error) {
+	absPath, err := tm.validatePath(filePath)
+	if err != nil {
+		return "", err
+	}
+	absWorkDir, err := filepath.Abs(tm.workDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid working directory")
+	}
+	return filepath.Rel(absWorkDir, absPath)
+}
+
 // GetFilesInfo lists files in a directory
 func (tm *ToolManager) GetFilesInfo(directory string) (string, error) {
 	if directory == "" {
@@ -52,6 +127,9 @@ func (tm *ToolManager) GetFilesInfo(directory string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if tm.overlay != nil {
+		return tm.simulatedFilesInfo(directory, absPath)
+	}
 
 	entries, err := os.ReadDir(absPath)
 	if err != nil {
@@ -75,6 +153,9 @@ func (tm *ToolManager) GetFileContent(filePath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if tm.overlay != nil {
+		return tm.simulatedFileContent(filePath, absPath)
+	}
 
 	fileInfo, err := os.Stat(absPath)
 	if err != nil {
@@ -85,8 +166,8 @@ func (tm *ToolManager) GetFileContent(filePath string) (string, error) {
 		return "", fmt.Errorf("cannot read directory as file")
 	}
 
-	if fileInfo.Size() > MaxFileSize {
-		return "", fmt.Errorf("file too large (%d bytes, max %d)", fileInfo.Size(), MaxFileSize)
+	if fileInfo.Size() > tm.policy.MaxFileSize {
+		return "", fmt.Errorf("file too large (%d bytes, max %d)", fileInfo.Size(), tm.policy.MaxFileSize)
 	}
 
 	content, err := os.ReadFile(absPath)
@@ -104,9 +185,50 @@ func (tm *ToolManager) WriteFile(filePath string, content string) (string, error
 		return "", err
 	}
 
+	if err := tm.CheckWrite(filePath); err != nil {
+		return "", err
+	}
+
+	// Write through symlinks rather than replacing them, as long as the target is inside
+	// the working directory and the write policy allows changing it too
+	target := absPath
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil && resolved != absPath {
+		rel, ok := tm.workDirRel(resolved)
+		if !ok {
+			return "", fmt.Errorf("%s is a symlink to a file outside the working directory", filePath)
+		}
+		if err := tm.CheckWrite(rel); err != nil {
+			return "", fmt.Errorf("%s is a symlink to %s: %w", filePath, filepath.ToSlash(rel), err)
+		}
+		target = resolved
+	}
+
 	// Check content size
-	if len(content) > MaxFileSize {
-		return "", fmt.Errorf("content too large (%d bytes, max %d)", len(content), MaxFileSize)
+	if int64(len(content)) > tm.policy.MaxFileSize {
+		return "", fmt.Errorf("content too large (%d bytes, max %d)", len(content), tm.policy.MaxFileSize)
+	}
+
+	if tm.overlay != nil {
+		rel, err := tm.overlayKey(filePath)
+		if err != nil {
+			return "", err
+		}
+		if exists, isDir := tm.statFile(rel, absPath); exists && isDir {
+			return "", fmt.Errorf("%s is a directory", filePath)
+		}
+		if original, err := tm.readFile(rel, absPath); err == nil {
+			content = matchLineEndings(string(original), content)
+		}
+		tm.overlay.write(rel, []byte(content))
+		tm.markWritten(filePath)
+		return fmt.Sprintf("[DRY RUN] File %s written with %d characters (simulated; later reads see the new content)", filePath, len(content)), nil
+	}
+
+	absPath = target
+
+	// Keep the existing file's line endings and trailing newline
+	if original, err := os.ReadFile(absPath); err == nil {
+		content = matchLineEndings(string(original), content)
 	}
 
 	// Create parent directories if needed
@@ -116,9 +238,37 @@ func (tm *ToolManager) WriteFile(filePath string, content string) (string, error
 	}
 
 	// Write file
-	if err := os.WriteFile(absPath, []byte(content), 0644); err != nil {
+	slog.Debug("writing file", "path", filePath, "bytes", len(content))
+	if err := writeFileAtomic(absPath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write file: %w", err)
 	}
+	tm.markWritten(filePath)
 
 	return fmt.Sprintf("File %s written successfully with %d characters", filePath, len(content)), nil
 }
+
+// HashFile returns "sha256:<hex>" of a file's content, or "" if the file does not exist
+func (tm *ToolManager) HashFile(filePath string) (string, error) {
+	rel, err := tm.overlayKey(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := tm.readFile(rel, filepath.Join(tm.workDir, rel))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// markWritten counts filePath towards Policy.MaxFilesWritten
+func (tm *ToolManager) markWritten(filePath string) {
+	if rel, err := tm.RelPath(filePath); err == nil {
+		tm.written[filepath.ToSlash(rel)] = true
+	}
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteFileChecksSymlinkTarget(t *testing.T) {
+	workDir := t.TempDir()
+	outside := t.TempDir()
+	files := map[string]string{
+		".git/config": "[core]\n",
+		"go.sum":      "sum\n",
+		"tool.exe":    "binary",
+		"notes.txt":   "notes\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(workDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(outside, "x.txt"), []byte("x\n"), 0644)
+
+	links := map[string]string{
+		"gitconfig.txt": filepath.Join(workDir, ".git", "config"),
+		"sums.txt":      filepath.Join(workDir, "go.sum"),
+		"tool.txt":      filepath.Join(workDir, "tool.exe"),
+		"escape.txt":    filepath.Join(outside, "x.txt"),
+		"link.txt":      filepath.Join(workDir, "notes.txt"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(workDir, name)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	tm := NewToolManager(workDir, false)
+	for _, tt := range []struct {
+		link string
+		want string // Error text; empty for success
+	}{
+		{"gitconfig.txt", `is protected (matches ".git/**")`},
+		{"sums.txt", `is protected (matches "go.sum")`},
+		{"tool.txt", "write policy:"},
+		{"escape.txt", "outside the working directory"},
+		{"link.txt", ""},
+	} {
+		_, err := tm.WriteFile(tt.link, "changed\n")
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("WriteFile(%s): %v", tt.link, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("WriteFile(%s) error = %v, want %q", tt.link, err, tt.want)
+		}
+	}
+
+	for name, content := range files {
+		if got, _ := os.ReadFile(filepath.Join(workDir, name)); string(got) != content && name != "notes.txt" {
+			t.Errorf("%s was changed through a symlink: %q", name, got)
+		}
+	}
+	if got, _ := os.ReadFile(filepath.Join(workDir, "notes.txt")); string(got) != "changed\n" {
+		t.Errorf("notes.txt = %q, want the write to go through the link", got)
+	}
+	if info, err := os.Lstat(filepath.Join(workDir, "link.txt")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("link.txt is no longer a symlink (%v)", err)
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"unicode/utf8"
 )
 
-// ValidateFileExtension checks if file extension is allowed for writing
-func ValidateFileExtension(filePath string) error {
-	allowedExts := []string{".go", ".py", ".sh", ".js", ".ts", ".md", ".txt", ".json", ".yaml", ".yml", ".toml", ".env"}
-
+// ValidateFileExtension checks if file extension is allowed for writing. An empty
+// entry in allowedExts allows files without an extension.
+func ValidateFileExtension(filePath string, allowedExts []string) error {
 	ext := filepath.Ext(filePath)
 	for _, allowed := range allowedExts {
 		if ext == allowed {
@@ -17,7 +17,17 @@ func ValidateFileExtension(filePath string) error {
 		}
 	}
 
-	return fmt.Errorf("file extension %s not allowed for writing (allowed: %v)", ext, allowedExts)
+	listed := make([]string, len(allowedExts))
+	for i, allowed := range allowedExts {
+		listed[i] = allowed
+		if allowed == "" {
+			listed[i] = "(none)"
+		}
+	}
+	if ext == "" {
+		return fmt.Errorf("files without an extension are not allowed for writing (allowed: %s)", strings.Join(listed, " "))
+	}
+	return fmt.Errorf("file extension %s not allowed for writing (allowed: %s)", ext, strings.Join(listed, " "))
 }
 
 // SanitizePath prevents directory traversal attacks
@@ -31,3 +41,14 @@ func SanitizePath(basePath, relativePath string) (string, error) {
 
 	return fullPath, nil
 }
+
+// CutAtRune returns at most the first n bytes of s, without splitting a UTF-8 sequence
+func CutAtRune(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
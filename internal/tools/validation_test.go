@@ -0,0 +1,25 @@
+package tools
+
+import "testing"
+
+func TestCutAtRune(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is two bytes
+		{"héllo", 3, "hé"},
+		{"日本語", 4, "日"},
+		{"日本語", 2, ""},
+		{"a😀b", 4, "a"},
+		{"a😀b", 5, "a😀"},
+	}
+	for _, tt := range tests {
+		if got := CutAtRune(tt.s, tt.n); got != tt.want {
+			t.Errorf("CutAtRune(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
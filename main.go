@@ -5,10 +5,13 @@ import (
 	"os"
 
 	"github.com/brandnova/nova-horizon-cli/cmd"
+	"github.com/brandnova/nova-horizon-cli/internal/logger"
 )
 
 func main() {
-	if err := cmd.Execute(); err != nil {
+	err := cmd.Execute()
+	logger.Close()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
 	}